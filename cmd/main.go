@@ -8,11 +8,26 @@ import (
 	"syscall"
 
 	_ "github.com/JscorpTech/auth/docs"
+	"github.com/JscorpTech/auth/internal/captcha"
 	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/geoip"
+	"github.com/JscorpTech/auth/internal/middlewares"
+	"github.com/JscorpTech/auth/internal/modules/audit"
+	auditHttp "github.com/JscorpTech/auth/internal/modules/audit/delivery/http"
 	"github.com/JscorpTech/auth/internal/modules/auth"
 	authHttp "github.com/JscorpTech/auth/internal/modules/auth/delivery/http"
+	"github.com/JscorpTech/auth/internal/modules/bot"
+	botHttp "github.com/JscorpTech/auth/internal/modules/bot/delivery/http"
+	"github.com/JscorpTech/auth/internal/modules/passkey"
+	passkeyHttp "github.com/JscorpTech/auth/internal/modules/passkey/delivery/http"
+	"github.com/JscorpTech/auth/internal/modules/smsguard"
+	smsGuardHttp "github.com/JscorpTech/auth/internal/modules/smsguard/delivery/http"
+	"github.com/JscorpTech/auth/internal/password"
+	"github.com/JscorpTech/auth/internal/phone"
+	"github.com/JscorpTech/auth/internal/ratelimit"
 	"github.com/JscorpTech/auth/internal/services"
-	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/JscorpTech/auth/internal/sms"
+	"github.com/JscorpTech/auth/internal/social"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/swaggo/files"
@@ -69,33 +84,99 @@ func main() {
 	// migrations
 	db.AutoMigrate(&auth.User{})
 	db.AutoMigrate(&auth.Otp{})
+	db.AutoMigrate(&auth.Totp{})
+	db.AutoMigrate(&auth.RecoveryCode{})
+	db.AutoMigrate(&auth.MagicLink{})
+	db.AutoMigrate(&auth.ExternalIdentity{})
+	db.AutoMigrate(&auth.Device{})
+	db.AutoMigrate(&auth.Permission{})
+	db.AutoMigrate(&auth.RolePermission{})
+	db.AutoMigrate(&passkey.Credential{})
+	db.AutoMigrate(&passkey.Session{})
+	db.AutoMigrate(&bot.Chat{})
+	db.AutoMigrate(&audit.Event{})
+	db.AutoMigrate(&ratelimit.Counter{})
+	db.AutoMigrate(&smsguard.Send{})
+	db.AutoMigrate(&smsguard.Limits{})
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate-phones" {
+		migratePhones(ctx, db, cfg, os.Args[2:])
+		return
+	}
 
 	router := gin.Default()
+	if err := middlewares.TrustProxies(router, cfg, logger); err != nil {
+		panic("failed to configure trusted proxies: " + err.Error())
+	}
 
 	router.GET("/api/v1/auth/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	api := router.Group("/api/v1/auth")
 
-	limiter := utils.NewRateLimiter(ctx, logger, 100)
+	phoneParser, err := phone.NewParser(cfg)
+	if err != nil {
+		panic("failed to configure phone parser: " + err.Error())
+	}
+	limiter, err := ratelimit.NewLimiter(ctx, cfg, db, logger)
+	if err != nil {
+		panic("failed to configure rate limiter: " + err.Error())
+	}
+	rateLimiter := middlewares.NewRateLimiter(limiter, phoneParser, cfg, logger)
+	captchaVerifier, err := captcha.NewVerifier(cfg)
+	if err != nil {
+		panic("failed to configure captcha: " + err.Error())
+	}
+	geoResolver, err := geoip.NewResolver(cfg)
+	if err != nil {
+		panic("failed to open geoip database: " + err.Error())
+	}
+	captchaMiddleware := middlewares.NewCaptcha(captchaVerifier, limiter, phoneParser, cfg, logger)
+	api.Use(rateLimiter.Policy("global", middlewares.ByIP))
+	api.Use(middlewares.ClientInfo())
+
+	// Telegram bot
+	var botUsecase bot.BotUsecase
+	var telegramSender sms.SmsProvider
+	if cfg.TelegramBotToken != "" {
+		botUsecase = bot.NewBotUsecase(bot.NewBotRepository(db), bot.NewClient(cfg.TelegramBotToken, cfg.TelegramAPIURL), logger)
+		telegramSender = botUsecase.Sender()
+		botHttp.RegisterBotRoutes(api, botHttp.NewBotHandler(botUsecase, cfg, logger))
+	}
 
-	api.Use(func(c *gin.Context) {
-		ip := c.ClientIP()
+	// Audit log
+	auditUsecase := audit.NewAuditUsecase(audit.NewAuditRepository(db), logger)
 
-		if !limiter.Allow(ip) {
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-				"status": false,
-				"error":  "Rate limit exceeded",
-			})
-			return
-		}
+	// SMS pumping himoyasi
+	emailSender := sms.NewEmail()
+	smsGuardUsecase := smsguard.NewSmsGuardUsecase(smsguard.NewSmsGuardRepository(db), auditUsecase, emailSender, cfg, logger)
 
-		c.Next()
-	})
+	passwordPolicy, err := password.NewPolicy(cfg)
+	if err != nil {
+		panic("failed to configure password policy: " + err.Error())
+	}
+	passwordHasher, err := password.NewHasherFromConfig(cfg)
+	if err != nil {
+		panic("failed to configure password hasher: " + err.Error())
+	}
 
 	// Auth routes
 	authRepository := auth.NewAuthRepository(db)
-	authUsecase := auth.NewAuthUsecase(authRepository, cfg, logger)
+	authUsecase := auth.NewAuthUsecase(authRepository, sms.NewEskiz(), emailSender, telegramSender, social.NewProviders(cfg, logger), auditUsecase, smsGuardUsecase, phoneParser, geoResolver, passwordPolicy, passwordHasher, cfg, logger)
+	if err := authUsecase.SeedPermissions(ctx); err != nil {
+		logger.Error("permissionlarni yaratishda xatolik", zap.Error(err))
+	}
 	authHandler := authHttp.NewAuthHandler(authUsecase, logger)
-	authHttp.RegisterAuthRoutes(cfg, api, authHandler)
+	authHttp.RegisterAuthRoutes(cfg, api, authHandler, rateLimiter, captchaMiddleware)
+	auditHttp.RegisterAuditRoutes(cfg, api, auditHttp.NewAuditHandler(auditUsecase, authUsecase, logger))
+	smsGuardHttp.RegisterSmsGuardRoutes(cfg, api, smsGuardHttp.NewSmsGuardHandler(smsGuardUsecase, logger), authUsecase)
+
+	// Passkey routes
+	passkeyRepository := passkey.NewPasskeyRepository(db)
+	passkeyUsecase, err := passkey.NewPasskeyUsecase(passkeyRepository, authRepository, phoneParser, cfg, logger)
+	if err != nil {
+		panic("failed to configure webauthn: " + err.Error())
+	}
+	passkeyHandler := passkeyHttp.NewPasskeyHandler(passkeyUsecase, authUsecase, logger)
+	passkeyHttp.RegisterPasskeyRoutes(cfg, api, passkeyHandler, rateLimiter)
 
 	go services.OtpClean(ctx, logger, authRepository)
 
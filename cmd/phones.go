@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/JscorpTech/auth/internal/phone"
+	"gorm.io/gorm"
+)
+
+// migratePhones mavjud raqamlarni E.164 ga o'tkazadi: go run ./cmd migrate-phones [-apply].
+// -apply siz faqat hisobot chiqariladi. To'qnashgan raqamlar o'zgartirilmaydi.
+func migratePhones(ctx context.Context, db *gorm.DB, cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("migrate-phones", flag.ExitOnError)
+	apply := flags.Bool("apply", false, "write normalized numbers, otherwise only report")
+	flags.Parse(args)
+
+	parser, err := phone.NewParser(cfg)
+	if err != nil {
+		panic("failed to configure phone parser: " + err.Error())
+	}
+	report, err := auth.MigratePhones(ctx, auth.NewAuthRepository(db), parser, *apply)
+	if err != nil {
+		panic("phone migration failed: " + err.Error())
+	}
+	for _, change := range report.Changes {
+		fmt.Printf("update user %d: %s -> %s\n", change.UserID, change.From, change.To)
+	}
+	for _, change := range report.Invalid {
+		fmt.Printf("invalid user %d: %s\n", change.UserID, change.From)
+	}
+	for normalized, group := range report.Collisions {
+		fmt.Printf("collision %s:\n", normalized)
+		for _, change := range group {
+			fmt.Printf("  user %d: %s\n", change.UserID, change.From)
+		}
+	}
+	status := "dry run, use -apply to write"
+	if *apply {
+		status = "applied"
+	}
+	fmt.Printf("%d users, %d updated, %d invalid, %d collisions (%s)\n",
+		report.Total, len(report.Changes), len(report.Invalid), len(report.Collisions), status)
+}
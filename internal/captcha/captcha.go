@@ -0,0 +1,42 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/config"
+)
+
+var (
+	ErrCaptchaRequired = errors.New("captcha required")
+	ErrCaptchaInvalid  = errors.New("invalid captcha")
+)
+
+// CaptchaVerifier mijoz yuborgan captcha tokenini provider orqali tekshiradi.
+// Token yaroqsiz bo'lsa ErrCaptchaInvalid, provider javob bermasa boshqa xato qaytadi.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token string, remoteIP string) error
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// NewVerifier CAPTCHA_PROVIDER bo'yicha verifier yaratadi, provider berilmagan bo'lsa
+// captcha o'chirilgan va nil qaytadi.
+func NewVerifier(cfg *config.Config) (CaptchaVerifier, error) {
+	switch cfg.CaptchaProvider {
+	case "":
+		return nil, nil
+	case "hcaptcha":
+		return NewHCaptcha(cfg.CaptchaSecret), nil
+	case "recaptcha":
+		return NewReCaptcha(cfg.CaptchaSecret, cfg.CaptchaMinScore), nil
+	case "turnstile":
+		return NewTurnstile(cfg.CaptchaSecret), nil
+	case "fake":
+		return NewFake(cfg.CaptchaSecret), nil
+	}
+	return nil, fmt.Errorf("unknown captcha provider %q", cfg.CaptchaProvider)
+}
@@ -0,0 +1,25 @@
+package captcha
+
+import (
+	"context"
+	"crypto/subtle"
+)
+
+// Fake tarmoqqa chiqmaydi va faqat berilgan tokenni qabul qiladi. Test va lokal muhit uchun.
+type Fake struct {
+	token string
+}
+
+func NewFake(token string) *Fake {
+	return &Fake{token: token}
+}
+
+func (f *Fake) Verify(ctx context.Context, token string, remoteIP string) error {
+	if token == "" {
+		return ErrCaptchaRequired
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(f.token)) != 1 {
+		return ErrCaptchaInvalid
+	}
+	return nil
+}
@@ -1,20 +1,253 @@
 package config
 
 import (
+	"maps"
 	"os"
+	"strconv"
+	"strings"
 
 	"go.uber.org/zap"
 )
 
+// SocialProvider bitta tashqi identity provider sozlamalari. Claims maydoni standart
+// claim nomlarini qayta belgilaydi: subject, email, email_verified, first_name, last_name.
+type SocialProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     []string
+	ClientSecret string
+	JWKSURL      string
+	Claims       map[string]string
+}
+
 type Config struct {
-	PrivateKey     []byte
-	PublicKey      []byte
-	Addr           string
-	AccessExp      int64
-	RefreshExp     int64
-	GoogleClientID string
-	DatabaseDsn    string
-	DatabaseType   string
+	PrivateKey []byte
+	PublicKey  []byte
+	Addr       string
+	AccessExp  int64
+	RefreshExp int64
+	MfaExp     int64
+	// ImpersonationExp admin impersonation tokeni muddati (daqiqa), refresh token berilmaydi.
+	ImpersonationExp int64
+	TotpIssuer       string
+	WebauthnRPID     string
+	WebauthnRPName   string
+	WebauthnOrigin   []string
+	MagicLinkURL     string
+	MagicLinkExp     int64
+	// MagicLinkSingleUse o'chirilsa link muddati tugaguncha qayta ishlatilishi mumkin.
+	MagicLinkSingleUse bool
+	GoogleClientID     string
+	SocialProviders    map[string]SocialProvider
+	TelegramBotToken   string
+	TelegramAPIURL     string
+	// TelegramWebhookSecret setWebhook dagi secret_token, webhook so'rovlari shu bilan tekshiriladi.
+	TelegramWebhookSecret string
+	// TelegramAuthExp Login Widget auth_date qancha vaqt (soniya) yaroqli.
+	TelegramAuthExp int64
+	// LockoutThreshold ketma-ket nechta noto'g'ri paroldan keyin akkaunt bloklanadi, 0 o'chiradi.
+	LockoutThreshold int64
+	// LockoutDuration birinchi bloklash muddati (daqiqa), har keyingisida ikki barobar oshadi.
+	LockoutDuration    int64
+	LockoutMaxDuration int64
+	PasswordMinLength  int64
+	// PasswordMaxLength baytlarda, bcrypt tanlanganda 72 dan oshmaydi.
+	PasswordMaxLength int64
+	// PasswordClasses majburiy belgilar turlari: lowercase, uppercase, digit, symbol.
+	PasswordClasses []string
+	// PasswordBreachedPath k-anonymity formatidagi SHA-1 korpus fayli yoki katalogi, bo'sh bo'lsa tekshirilmaydi.
+	PasswordBreachedPath string
+	// PasswordHasher yangi hashlar uchun algoritm: Django formatlari (django_argon2,
+	// pbkdf2_sha256, bcrypt_sha256), argon2id yoki bcrypt. Boshqa formatdagi yoki kuchsizroq
+	// parametrli hashlar muvaffaqiyatli kirishda qayta hashlanadi, Django hashlari esa
+	// faqat Django formatiga.
+	PasswordHasher string
+	// Argon2Memory KiB da. Argon2 va bcrypt parametrlari faqat argon2id va bcrypt formatlari
+	// uchun, Django formatlari Django ning standart parametrlaridan foydalanadi.
+	Argon2Memory  int64
+	Argon2Time    int64
+	Argon2Threads int64
+	BcryptCost    int64
+	// RateLimitBackend token_bucket, sliding_window yoki sql (bir nechta replika uchun).
+	RateLimitBackend string
+	// RateLimitPolicies nomlangan limitlar, "global" barcha so'rovlarga IP bo'yicha qo'llanadi.
+	RateLimitPolicies map[string]RateLimitPolicy
+	// TrustedProxies X-Forwarded-For qabul qilinadigan proxy IP yoki CIDR lari, bo'sh bo'lsa
+	// mijoz IP si faqat TCP manzildan olinadi.
+	TrustedProxies []string
+	// TrustedPlatformHeader masalan CF-Connecting-IP, faqat TrustedProxies dan kelganda o'qiladi.
+	TrustedPlatformHeader string
+	// AdminIPAllowlist bo'sh bo'lmasa admin API ga faqat shu tarmoqlardan kirish mumkin.
+	AdminIPAllowlist []string
+	AdminIPDenylist  []string
+	// CaptchaProvider hcaptcha, recaptcha, turnstile yoki fake, bo'sh bo'lsa captcha o'chirilgan.
+	CaptchaProvider string
+	CaptchaSecret   string
+	// CaptchaMinScore reCAPTCHA v3 uchun minimal score.
+	CaptchaMinScore float64
+	// CaptchaRoutes doim captcha talab qilinadigan route lar (register, password_reset).
+	CaptchaRoutes []string
+	// CaptchaIPThreshold va CaptchaPrefixThreshold CaptchaWindow ichida IP yoki raqam
+	// prefiksidan shuncha SMS so'rovidan keyin captcha talab qilinadi, 0 o'chiradi.
+	CaptchaIPThreshold     int64
+	CaptchaPrefixThreshold int64
+	CaptchaPrefixLength    int64
+	CaptchaWindow          int64
+	// Sms*DailyLimit oxirgi 24 soatda raqamga, IP dan va raqam prefiksiga OTP yuborish limiti, 0 o'chiradi.
+	// Admin API orqali o'zgartirilgandan keyin bazadagi qiymatlar ishlatiladi.
+	SmsPhoneDailyLimit  int64
+	SmsIPDailyLimit     int64
+	SmsPrefixDailyLimit int64
+	SmsPrefixLength     int64
+	// SmsBlockedPrefixes premium raqam prefikslari, SmsAllowedCountries bo'sh bo'lmasa faqat
+	// shu mamlakat kodlariga yuboriladi.
+	SmsBlockedPrefixes  []string
+	SmsAllowedCountries []string
+	// SmsAlertFactor oxirgi soat odatdagidan shuncha marta ko'p bo'lsa ogohlantirish, 0 o'chiradi.
+	SmsAlertFactor float64
+	SmsAlertMin    int64
+	SmsAlertEmails []string
+	// PhoneParser libphonenumber yoki e164, PhoneDefaultRegion mamlakat kodisiz kiritilgan
+	// raqamlar uchun (ISO 3166-1, masalan UZ).
+	PhoneParser        string
+	PhoneDefaultRegion string
+	// GeoIPPath MaxMind .mmdb fayli, bo'sh bo'lsa qurilma mamlakati aniqlanmaydi.
+	GeoIPPath string
+	// DeviceAlerts yangi qurilmadan kirilganda foydalanuvchiga xabar yuboriladi,
+	// DeviceRevokeURL xabardagi sessiyani bekor qilish havolasi.
+	DeviceAlerts    bool
+	DeviceRevokeURL string
+	DatabaseDsn     string
+	DatabaseType    string
+}
+
+func getEnv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getEnvMap(key string) map[string]string {
+	items := make(map[string]string)
+	for _, item := range getEnvList(key, nil) {
+		if name, value, ok := strings.Cut(item, "="); ok {
+			items[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+	return items
+}
+
+// socialDefaults ma'lum providerlar uchun issuer va JWKS manzillari.
+var socialDefaults = map[string]SocialProvider{
+	"google": {Issuer: "https://accounts.google.com", JWKSURL: "https://www.googleapis.com/oauth2/v3/certs"},
+	"apple":  {Issuer: "https://appleid.apple.com", JWKSURL: "https://appleid.apple.com/auth/keys"},
+	"github": {Issuer: "https://api.github.com"},
+}
+
+// loadSocialProviders SOCIAL_PROVIDERS=google,apple,keycloak ro'yxatidagi har bir provider
+// uchun SOCIAL_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _JWKS_URL va _CLAIMS ni o'qiydi.
+func loadSocialProviders(googleClientID string) map[string]SocialProvider {
+	names := getEnvList("SOCIAL_PROVIDERS", []string{"google"})
+	providers := make(map[string]SocialProvider, len(names))
+	for _, name := range names {
+		name = strings.ToLower(name)
+		prefix := "SOCIAL_" + strings.ToUpper(name) + "_"
+		provider := socialDefaults[name]
+		provider.Name = name
+		provider.Issuer = getEnv(prefix+"ISSUER", provider.Issuer)
+		provider.ClientID = getEnvList(prefix+"CLIENT_ID", nil)
+		provider.ClientSecret = os.Getenv(prefix + "CLIENT_SECRET")
+		provider.JWKSURL = getEnv(prefix+"JWKS_URL", provider.JWKSURL)
+		provider.Claims = getEnvMap(prefix + "CLAIMS")
+		if name == "google" && len(provider.ClientID) == 0 && googleClientID != "" {
+			provider.ClientID = []string{googleClientID}
+		}
+		providers[name] = provider
+	}
+	return providers
+}
+
+// RateLimitPolicy Window (soniya) davomida ruxsat etilgan Requests ta so'rov.
+type RateLimitPolicy struct {
+	Requests int64
+	Window   int64
+}
+
+// rateLimitDefaults login IP va raqam juftligiga, login_ip bitta IP dan barcha raqamlarga.
+// otp_verify OTP kodini tekshirish uchun parol byudjetidan alohida: raqamga va IP ga.
+var rateLimitDefaults = map[string]RateLimitPolicy{
+	"login":         {Requests: 10, Window: 300},
+	"login_ip":      {Requests: 30, Window: 300},
+	"otp":           {Requests: 3, Window: 600},
+	"otp_ip":        {Requests: 20, Window: 3600},
+	"otp_verify":    {Requests: 5, Window: 600},
+	"otp_verify_ip": {Requests: 30, Window: 600},
+	"refresh":       {Requests: 30, Window: 60},
+	"reauth":        {Requests: 10, Window: 300},
+	"mfa":           {Requests: 5, Window: 300},
+}
+
+// loadRateLimitPolicies standart limitlarni RATE_LIMIT_POLICIES=login=5/60,otp=3/600 bilan
+// almashtiradi. global limit RATE_LIMIT_REQUESTS va RATE_LIMIT_WINDOW dan olinadi.
+func loadRateLimitPolicies(logger *zap.Logger) map[string]RateLimitPolicy {
+	policies := maps.Clone(rateLimitDefaults)
+	policies["global"] = RateLimitPolicy{
+		Requests: getEnvInt("RATE_LIMIT_REQUESTS", 100),
+		Window:   getEnvInt("RATE_LIMIT_WINDOW", 60),
+	}
+	for name, value := range getEnvMap("RATE_LIMIT_POLICIES") {
+		requests, window, _ := strings.Cut(value, "/")
+		policy := RateLimitPolicy{}
+		var err error
+		if policy.Requests, err = strconv.ParseInt(requests, 10, 64); err == nil {
+			policy.Window, err = strconv.ParseInt(window, 10, 64)
+		}
+		if err != nil || policy.Requests <= 0 || policy.Window <= 0 {
+			logger.Warn("noto'g'ri rate limit sozlamasi", zap.String("policy", name), zap.String("value", value))
+			continue
+		}
+		policies[name] = policy
+	}
+	return policies
 }
 
 func NewConfig(logger *zap.Logger) *Config {
@@ -39,13 +272,66 @@ func NewConfig(logger *zap.Logger) *Config {
 	}
 
 	return &Config{
-		PrivateKey:     privKey,
-		PublicKey:      pubKey,
-		Addr:           os.Getenv("ADDR"),
-		AccessExp:      60,
-		RefreshExp:     43200,
-		GoogleClientID: os.Getenv("GOOGLE_CLIENT_ID"),
-		DatabaseType:   os.Getenv("DATABASE_TYPE"),
-		DatabaseDsn:    os.Getenv("DATABASE_DSN"),
+		PrivateKey:             privKey,
+		PublicKey:              pubKey,
+		Addr:                   os.Getenv("ADDR"),
+		AccessExp:              60,
+		RefreshExp:             43200,
+		MfaExp:                 getEnvInt("MFA_EXP", 5),
+		ImpersonationExp:       getEnvInt("IMPERSONATION_EXP", 15),
+		TotpIssuer:             getEnv("TOTP_ISSUER", "JscorpTech"),
+		WebauthnRPID:           getEnv("WEBAUTHN_RP_ID", "localhost"),
+		WebauthnRPName:         getEnv("WEBAUTHN_RP_NAME", "JscorpTech"),
+		WebauthnOrigin:         getEnvList("WEBAUTHN_ORIGINS", []string{"http://localhost:8080"}),
+		MagicLinkURL:           getEnv("MAGIC_LINK_URL", "http://localhost:8080/auth/magic-link"),
+		MagicLinkExp:           getEnvInt("MAGIC_LINK_EXP", 15),
+		MagicLinkSingleUse:     getEnvBool("MAGIC_LINK_SINGLE_USE", true),
+		GoogleClientID:         os.Getenv("GOOGLE_CLIENT_ID"),
+		TelegramBotToken:       os.Getenv("TELEGRAM_BOT_TOKEN"),
+		TelegramAPIURL:         getEnv("TELEGRAM_API_URL", "https://api.telegram.org"),
+		TelegramWebhookSecret:  os.Getenv("TELEGRAM_WEBHOOK_SECRET"),
+		TelegramAuthExp:        getEnvInt("TELEGRAM_AUTH_EXP", 86400),
+		LockoutThreshold:       getEnvInt("LOCKOUT_THRESHOLD", 5),
+		LockoutDuration:        getEnvInt("LOCKOUT_DURATION", 15),
+		LockoutMaxDuration:     getEnvInt("LOCKOUT_MAX_DURATION", 1440),
+		PasswordMinLength:      getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordMaxLength:      getEnvInt("PASSWORD_MAX_LENGTH", 72),
+		PasswordClasses:        getEnvList("PASSWORD_CLASSES", []string{"lowercase", "uppercase", "digit"}),
+		PasswordBreachedPath:   os.Getenv("PASSWORD_BREACHED_PATH"),
+		PasswordHasher:         getEnv("PASSWORD_HASHER", "django_argon2"),
+		Argon2Memory:           getEnvInt("ARGON2_MEMORY", 65536),
+		Argon2Time:             getEnvInt("ARGON2_TIME", 3),
+		Argon2Threads:          getEnvInt("ARGON2_THREADS", 2),
+		BcryptCost:             getEnvInt("BCRYPT_COST", 10),
+		RateLimitBackend:       getEnv("RATE_LIMIT_BACKEND", "token_bucket"),
+		RateLimitPolicies:      loadRateLimitPolicies(logger),
+		TrustedProxies:         getEnvList("TRUSTED_PROXIES", nil),
+		TrustedPlatformHeader:  os.Getenv("TRUSTED_PLATFORM_HEADER"),
+		AdminIPAllowlist:       getEnvList("ADMIN_IP_ALLOWLIST", nil),
+		AdminIPDenylist:        getEnvList("ADMIN_IP_DENYLIST", nil),
+		CaptchaProvider:        os.Getenv("CAPTCHA_PROVIDER"),
+		CaptchaSecret:          os.Getenv("CAPTCHA_SECRET"),
+		CaptchaMinScore:        getEnvFloat("CAPTCHA_MIN_SCORE", 0.5),
+		CaptchaRoutes:          getEnvList("CAPTCHA_ROUTES", nil),
+		CaptchaIPThreshold:     getEnvInt("CAPTCHA_IP_THRESHOLD", 5),
+		CaptchaPrefixThreshold: getEnvInt("CAPTCHA_PREFIX_THRESHOLD", 100),
+		CaptchaPrefixLength:    getEnvInt("CAPTCHA_PREFIX_LENGTH", 5),
+		CaptchaWindow:          getEnvInt("CAPTCHA_WINDOW", 3600),
+		SmsPhoneDailyLimit:     getEnvInt("SMS_PHONE_DAILY_LIMIT", 10),
+		SmsIPDailyLimit:        getEnvInt("SMS_IP_DAILY_LIMIT", 50),
+		SmsPrefixDailyLimit:    getEnvInt("SMS_PREFIX_DAILY_LIMIT", 1000),
+		SmsPrefixLength:        getEnvInt("SMS_PREFIX_LENGTH", 5),
+		SmsBlockedPrefixes:     getEnvList("SMS_BLOCKED_PREFIXES", nil),
+		SmsAllowedCountries:    getEnvList("SMS_ALLOWED_COUNTRIES", nil),
+		SmsAlertFactor:         getEnvFloat("SMS_ALERT_FACTOR", 5),
+		SmsAlertMin:            getEnvInt("SMS_ALERT_MIN", 20),
+		SmsAlertEmails:         getEnvList("SMS_ALERT_EMAILS", nil),
+		PhoneParser:            getEnv("PHONE_PARSER", "libphonenumber"),
+		PhoneDefaultRegion:     strings.ToUpper(getEnv("PHONE_DEFAULT_REGION", "UZ")),
+		GeoIPPath:              os.Getenv("GEOIP_DB_PATH"),
+		DeviceAlerts:           getEnvBool("DEVICE_ALERTS", true),
+		DeviceRevokeURL:        getEnv("DEVICE_REVOKE_URL", "http://localhost:8080/auth/devices/revoke"),
+		DatabaseType:           os.Getenv("DATABASE_TYPE"),
+		DatabaseDsn:            os.Getenv("DATABASE_DSN"),
 	}
 }
@@ -0,0 +1,26 @@
+package dto
+
+type PageRequest struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+func (p *PageRequest) Normalize() {
+	if p.Page == 0 {
+		p.Page = 1
+	}
+	if p.PageSize == 0 {
+		p.PageSize = 20
+	}
+}
+
+func (p *PageRequest) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+type PageResponse struct {
+	Items    any   `json:"items"`
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+}
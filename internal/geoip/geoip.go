@@ -0,0 +1,42 @@
+package geoip
+
+import (
+	"net"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/oschwald/geoip2-golang"
+)
+
+// CountryResolver IP manzil mamlakatini ISO 3166-1 kodi bilan qaytaradi, aniqlab
+// bo'lmasa bo'sh satr.
+type CountryResolver interface {
+	Country(net.IP) string
+}
+
+// MaxMind lokal GeoLite2/GeoIP2 Country (yoki City) .mmdb fayli, so'rovlar tarmoqqa chiqmaydi.
+type MaxMind struct {
+	reader *geoip2.Reader
+}
+
+// NewResolver GEOIP_DB_PATH berilmagan bo'lsa nil qaytaradi, mamlakat aniqlanmaydi.
+func NewResolver(cfg *config.Config) (CountryResolver, error) {
+	if cfg.GeoIPPath == "" {
+		return nil, nil
+	}
+	reader, err := geoip2.Open(cfg.GeoIPPath)
+	if err != nil {
+		return nil, err
+	}
+	return &MaxMind{reader: reader}, nil
+}
+
+func (m *MaxMind) Country(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+	record, err := m.reader.Country(ip)
+	if err != nil {
+		return ""
+	}
+	return record.Country.IsoCode
+}
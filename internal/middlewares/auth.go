@@ -1,14 +1,20 @@
 package middlewares
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/JscorpTech/auth/internal/config"
 	"github.com/JscorpTech/auth/internal/dto"
+	"github.com/JscorpTech/auth/internal/modules/audit"
+	"github.com/JscorpTech/auth/internal/modules/auth"
 	"github.com/JscorpTech/auth/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
@@ -41,6 +47,123 @@ func AuthMiddleware(cfg *config.Config, logger *zap.Logger) gin.HandlerFunc {
 			return
 		}
 		c.Set("user", claims)
+		userID, _ := claims["user_id"].(float64)
+		principal := uint(userID)
+		// impersonation tokenida haqiqiy so'rov egasi (admin) "actor" kalitida bo'ladi
+		if actorID, ok := auth.ActorFromClaims(claims); ok {
+			c.Set("actor", actorID)
+			principal = actorID
+		}
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// UserLoader token egasini bazadan yuklaydi, auth.AuthUsecase shu interfeysga mos.
+type UserLoader interface {
+	CurrentUser(context.Context, jwt.MapClaims) (*auth.User, error)
+}
+
+// ActiveUser AuthMiddleware dan keyin qo'yiladi. Token egasi o'chirilgan, bloklangan yoki
+// sessiyasi (qurilmasi) bekor qilingan bo'lsa access token muddati tugashini kutmasdan
+// so'rovni rad etadi. Yuklangan foydalanuvchi "current_user" kalitida bo'ladi.
+func ActiveUser(users UserLoader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := users.CurrentUser(c.Request.Context(), c.MustGet("user").(jwt.MapClaims))
+		if err != nil {
+			if status, ok := auth.AccountStatus(err); ok {
+				dto.JSON(c, http.StatusForbidden, status, err.Error())
+			} else {
+				dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+			}
+			c.Abort()
+			return
+		}
+		c.Set("current_user", user)
+		c.Next()
+	}
+}
+
+// Actor impersonation qilayotgan admin ID sini qaytaradi.
+func Actor(c *gin.Context) (uint, bool) {
+	value, ok := c.Get("actor")
+	if !ok {
+		return 0, false
+	}
+	actorID, ok := value.(uint)
+	return actorID, ok
+}
+
+// DenyImpersonation admin boshqa foydalanuvchi nomidan bajara olmaydigan amallarni yopadi.
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := Actor(c); ok {
+			dto.JSON(c, http.StatusForbidden, nil, auth.ErrImpersonationForbidden.Error())
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// stepUpChallenge RFC 9470 bo'yicha mijozga qanday qayta autentifikatsiya kerakligini bildiradi.
+func stepUpChallenge(c *gin.Context, params string, msg string) {
+	c.Header("WWW-Authenticate", `Bearer error="insufficient_user_authentication", `+params)
+	dto.JSON(c, http.StatusUnauthorized, nil, msg)
+	c.Abort()
+}
+
+// RequireRecentAuth AuthMiddleware dan keyin qo'yiladi. Token auth_time claimi maxAge dan
+// eski bo'lsa so'rovni rad etadi, mijoz /reauth orqali sessiyani yangilashi kerak.
+func RequireRecentAuth(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet("user").(jwt.MapClaims)
+		authCtx := auth.AuthContextFromClaims(claims)
+		if authCtx.AuthTime.IsZero() || time.Since(authCtx.AuthTime) > maxAge {
+			stepUpChallenge(c, fmt.Sprintf("max_age=%d", int(maxAge.Seconds())), "Recent authentication required")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireACR token acr darajasi talab qilinganidan past bo'lsa so'rovni rad etadi.
+func RequireACR(acr string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet("user").(jwt.MapClaims)
+		authCtx := auth.AuthContextFromClaims(claims)
+		if !auth.AcrSatisfies(authCtx.Acr, acr) {
+			stepUpChallenge(c, fmt.Sprintf("acr_values=%q", acr), "Stronger authentication required")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole token role claimi berilgan rollardan biri bo'lmasa 403 qaytaradi.
+// Rol o'zgarishi keyingi access tokendan boshlab kuchga kiradi.
+func RequireRole(roles ...auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet("user").(jwt.MapClaims)
+		role, _ := claims["role"].(string)
+		if !slices.Contains(roles, auth.Role(role)) {
+			dto.JSON(c, http.StatusForbidden, nil, "Permission denied")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission token permissions claimida ruxsat (yoki "*") bo'lmasa 403 qaytaradi.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet("user").(jwt.MapClaims)
+		if !auth.HasPermission(auth.PermissionsFromClaims(claims), permission) {
+			dto.JSON(c, http.StatusForbidden, nil, "Permission denied")
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
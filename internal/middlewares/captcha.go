@@ -0,0 +1,100 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/captcha"
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/dto"
+	"github.com/JscorpTech/auth/internal/phone"
+	"github.com/JscorpTech/auth/internal/ratelimit"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const CaptchaHeader = "X-Captcha-Token"
+
+// Captcha SMS yuboradigan route larda captcha talab qiladi: CAPTCHA_ROUTES dagi route larda
+// doim, qolganlarida IP yoki raqam prefiksidan yuborishlar chegaradan oshgandan keyin.
+type Captcha struct {
+	verifier     captcha.CaptchaVerifier
+	limiter      ratelimit.Limiter
+	phones       phone.Parser
+	routes       []string
+	ip           ratelimit.Limit
+	prefix       ratelimit.Limit
+	prefixLength int
+	logger       *zap.Logger
+}
+
+func NewCaptcha(verifier captcha.CaptchaVerifier, limiter ratelimit.Limiter, phones phone.Parser, cfg *config.Config, logger *zap.Logger) *Captcha {
+	window := time.Duration(cfg.CaptchaWindow) * time.Second
+	return &Captcha{
+		verifier:     verifier,
+		limiter:      limiter,
+		phones:       phones,
+		routes:       cfg.CaptchaRoutes,
+		ip:           ratelimit.Limit{Requests: int(cfg.CaptchaIPThreshold), Window: window},
+		prefix:       ratelimit.Limit{Requests: int(cfg.CaptchaPrefixThreshold), Window: window},
+		prefixLength: int(cfg.CaptchaPrefixLength),
+		logger:       logger,
+	}
+}
+
+// Require token X-Captcha-Token headerida yuboriladi. Captcha kerak bo'lsa va token
+// bo'lmasa 403 captcha_required, yaroqsiz bo'lsa captcha_invalid qaytadi.
+func (r *Captcha) Require(route string) gin.HandlerFunc {
+	always := slices.Contains(r.routes, route)
+	return func(c *gin.Context) {
+		if r.verifier == nil {
+			c.Next()
+			return
+		}
+		// hisoblagich route lar uchun umumiy, doimiy captcha li route dagi yuborishlar ham hisoblanadi
+		busy := r.busy(c)
+		if !always && !busy {
+			c.Next()
+			return
+		}
+		err := r.verifier.Verify(c.Request.Context(), c.GetHeader(CaptchaHeader), c.ClientIP())
+		switch {
+		case err == nil:
+			c.Next()
+		case errors.Is(err, captcha.ErrCaptchaRequired):
+			dto.JSON(c, http.StatusForbidden, gin.H{"code": "captcha_required"}, "Captcha required")
+			c.Abort()
+		case errors.Is(err, captcha.ErrCaptchaInvalid):
+			dto.JSON(c, http.StatusForbidden, gin.H{"code": "captcha_invalid"}, "Invalid captcha")
+			c.Abort()
+		default:
+			r.logger.Error("captcha verify error", zap.String("route", route), zap.Error(err))
+			dto.JSON(c, http.StatusServiceUnavailable, nil, "Captcha verification unavailable")
+			c.Abort()
+		}
+	}
+}
+
+// busy IP yoki raqam prefiksi bo'yicha yuborishlar chegaradan oshganini bildiradi.
+func (r *Captcha) busy(c *gin.Context) bool {
+	busy := false
+	if r.ip.Requests > 0 {
+		busy = r.over(c, "captcha:ip:"+c.ClientIP(), r.ip) || busy
+	}
+	if phone, ok := normalizedPhone(c, r.phones); ok && r.prefix.Requests > 0 {
+		busy = r.over(c, "captcha:prefix:"+utils.PhonePrefix(phone, r.prefixLength), r.prefix) || busy
+	}
+	return busy
+}
+
+func (r *Captcha) over(c *gin.Context, key string, limit ratelimit.Limit) bool {
+	result, err := r.limiter.Allow(c.Request.Context(), key, limit)
+	if err != nil {
+		r.logger.Error("captcha counter error", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	return !result.Allowed
+}
@@ -0,0 +1,18 @@
+package middlewares
+
+import (
+	"github.com/JscorpTech/auth/internal/modules/audit"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientInfo so'rov IP manzili va User-Agent ini audit hodisalari uchun request kontekstiga qo'yadi.
+func ClientInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := audit.WithClient(c.Request.Context(), audit.Client{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/dto"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TrustProxies mijoz IP sini faqat ishonchli proxy lar yuborgan headerlardan oladi.
+// Platforma headeri gin ning TrustedPlatform i orqali emas, RemoteIPHeaders orqali
+// beriladi: TrustedPlatform headerni istalgan manzildan qabul qiladi va uni soxtalashtirish mumkin.
+func TrustProxies(router *gin.Engine, cfg *config.Config, logger *zap.Logger) error {
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return err
+	}
+	if cfg.TrustedPlatformHeader != "" {
+		router.RemoteIPHeaders = []string{cfg.TrustedPlatformHeader}
+		if len(cfg.TrustedProxies) == 0 {
+			logger.Warn("TRUSTED_PLATFORM_HEADER TRUSTED_PROXIES siz e'tiborga olinmaydi", zap.String("header", cfg.TrustedPlatformHeader))
+		}
+	}
+	return nil
+}
+
+// IPFilter denylist dagi tarmoqlarni, allowlist berilgan bo'lsa undan tashqaridagilarni
+// 403 bilan rad etadi. Noto'g'ri CIDR ishga tushishda panic qiladi.
+func IPFilter(allow []string, deny []string) gin.HandlerFunc {
+	allowed := mustParsePrefixes(allow)
+	denied := mustParsePrefixes(deny)
+	return func(c *gin.Context) {
+		ip, err := netip.ParseAddr(c.ClientIP())
+		if err != nil || containsIP(denied, ip) || (len(allowed) > 0 && !containsIP(allowed, ip)) {
+			dto.JSON(c, http.StatusForbidden, nil, "Access denied")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// AdminIPFilter admin route lari uchun config dagi ro'yxatlar.
+func AdminIPFilter(cfg *config.Config) gin.HandlerFunc {
+	return IPFilter(cfg.AdminIPAllowlist, cfg.AdminIPDenylist)
+}
+
+func containsIP(prefixes []netip.Prefix, ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// mustParsePrefixes CIDR yoki yakka IP manzillarni qabul qiladi.
+func mustParsePrefixes(values []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			ip, err := netip.ParseAddr(value)
+			if err != nil {
+				panic("invalid ip: " + value)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			panic("invalid cidr: " + value)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
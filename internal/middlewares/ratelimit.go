@@ -0,0 +1,187 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/phone"
+	"github.com/JscorpTech/auth/internal/ratelimit"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// RateLimitKey so'rovdan limit kalitini oladi. false qaytsa (masalan body da telefon
+// yo'q) shu siyosat so'rovga qo'llanmaydi.
+type RateLimitKey func(*gin.Context) (string, bool)
+
+func ByIP(c *gin.Context) (string, bool) {
+	return "ip:" + c.ClientIP(), true
+}
+
+// ByPhone JSON body dagi phone maydoni, E.164 ga keltirilgan holda, shunda bitta raqamni
+// turli ko'rinishda yozib limitni aylanib o'tib bo'lmaydi.
+func (r *RateLimiter) ByPhone(c *gin.Context) (string, bool) {
+	phone, ok := normalizedPhone(c, r.phones)
+	if !ok {
+		return "", false
+	}
+	return "phone:" + phone, true
+}
+
+// normalizedPhone raqam noto'g'ri bo'lsa kiritilganicha qaytaradi, so'rovni handler rad etadi.
+func normalizedPhone(c *gin.Context, phones phone.Parser) (string, bool) {
+	raw, ok := bodyField(c, "phone")
+	if !ok {
+		return "", false
+	}
+	if normalized, err := phones.Normalize(raw); err == nil {
+		return normalized, true
+	}
+	return raw, true
+}
+
+// ByMfaToken mfa_token egasi bo'yicha, shunda IP almashtirib ikkinchi faktorni taxmin qilib
+// bo'lmaydi. Imzosi noto'g'ri token boshqa foydalanuvchi byudjetini sarflay olmaydi.
+func (r *RateLimiter) ByMfaToken(c *gin.Context) (string, bool) {
+	token, ok := bodyField(c, "mfa_token")
+	if !ok {
+		return "", false
+	}
+	claims, err := utils.VerifyJWT(token, r.publicKey)
+	if err != nil || claims["token_type"] != "mfa" {
+		return "", false
+	}
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return "", false
+	}
+	return "user:" + strconv.FormatInt(int64(userID), 10), true
+}
+
+// bodyField JSON body dagi satr maydon, body handler uchun qayta tiklanadi.
+func bodyField(c *gin.Context, name string) (string, bool) {
+	if c.Request.Body == nil {
+		return "", false
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return "", false
+	}
+	var payload map[string]any
+	if json.Unmarshal(body, &payload) != nil {
+		return "", false
+	}
+	value, ok := payload[name].(string)
+	return value, ok && value != ""
+}
+
+// ByUser AuthMiddleware dan keyin ishlatiladi.
+func ByUser(c *gin.Context) (string, bool) {
+	claims, ok := c.Get("user")
+	if !ok {
+		return "", false
+	}
+	userID, ok := claims.(jwt.MapClaims)["user_id"].(float64)
+	if !ok {
+		return "", false
+	}
+	return "user:" + strconv.FormatInt(int64(userID), 10), true
+}
+
+// KeyAll kalitlarni birlashtiradi, masalan IP va telefon juftligi uchun alohida limit.
+func KeyAll(keys ...RateLimitKey) RateLimitKey {
+	return func(c *gin.Context) (string, bool) {
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			part, ok := key(c)
+			if !ok {
+				return "", false
+			}
+			parts = append(parts, part)
+		}
+		return strings.Join(parts, "|"), true
+	}
+}
+
+// RateLimiter config dagi nomlangan siyosatlarni route larga biriktiradi.
+type RateLimiter struct {
+	limiter  ratelimit.Limiter
+	phones   phone.Parser
+	policies map[string]config.RateLimitPolicy
+	// publicKey ByMfaToken da token imzosini tekshirish uchun
+	publicKey []byte
+	logger    *zap.Logger
+}
+
+func NewRateLimiter(limiter ratelimit.Limiter, phones phone.Parser, cfg *config.Config, logger *zap.Logger) *RateLimiter {
+	return &RateLimiter{
+		limiter:   limiter,
+		phones:    phones,
+		policies:  cfg.RateLimitPolicies,
+		publicKey: cfg.PublicKey,
+		logger:    logger,
+	}
+}
+
+// Policy name siyosati bo'yicha key ga alohida byudjet ajratadi. Limiter ishlamay qolsa
+// (masalan baza xatosi) so'rov o'tkazib yuboriladi.
+func (r *RateLimiter) Policy(name string, key RateLimitKey) gin.HandlerFunc {
+	policy, ok := r.policies[name]
+	if !ok {
+		panic("unknown rate limit policy: " + name)
+	}
+	limit := ratelimit.Limit{
+		Requests: int(policy.Requests),
+		Window:   time.Duration(policy.Window) * time.Second,
+	}
+	return func(c *gin.Context) {
+		value, ok := key(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		result, err := r.limiter.Allow(c.Request.Context(), name+":"+value, limit)
+		if err != nil {
+			r.logger.Error("rate limit error", zap.String("policy", name), zap.Error(err))
+			c.Next()
+			return
+		}
+		setRateLimitHeaders(c, limit, result)
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"status": false,
+				"error":  "Rate limit exceeded",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// setRateLimitHeaders bir nechta siyosat qo'llanganda eng kam qolgani ko'rsatiladi.
+func setRateLimitHeaders(c *gin.Context, limit ratelimit.Limit, result ratelimit.Result) {
+	if previous, ok := c.Get("ratelimit_remaining"); ok && previous.(int) < result.Remaining {
+		return
+	}
+	c.Set("ratelimit_remaining", result.Remaining)
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Window.Seconds())))
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,29 @@
+package audit
+
+import "context"
+
+type clientKey struct{}
+
+// Client so'rov manbasi. Middleware request kontekstiga qo'yadi, usecase lar esa
+// hodisa yozishda shu yerdan oladi.
+type Client struct {
+	IP        string
+	UserAgent string
+	// ActorID token egasi, impersonation paytida esa haqiqiy so'rov egasi (admin)
+	ActorID *uint
+}
+
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+func ClientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
+
+func WithActor(ctx context.Context, actorID uint) context.Context {
+	client := ClientFromContext(ctx)
+	client.ActorID = &actorID
+	return WithClient(ctx, client)
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/JscorpTech/auth/internal/dto"
+	"github.com/JscorpTech/auth/internal/modules/audit"
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+type AuditHandler struct {
+	usecase audit.AuditUsecase
+	auth    auth.AuthUsecase
+	logger  *zap.Logger
+}
+
+func NewAuditHandler(usecase audit.AuditUsecase, authUsecase auth.AuthUsecase, logger *zap.Logger) *AuditHandler {
+	return &AuditHandler{
+		usecase: usecase,
+		auth:    authUsecase,
+		logger:  logger,
+	}
+}
+
+// @Router /api/v1/auth/admin/audit [get]
+// @Summary Query security audit log
+// @Tags admin
+// @Produce json
+// @Param type query string false "Event type, e.g. login.failure"
+// @Param actor_id query int false "Actor user ID"
+// @Param subject_id query int false "Subject user ID"
+// @Param ip query string false "Client IP"
+// @Param from query string false "From (RFC 3339)"
+// @Param to query string false "To (RFC 3339, exclusive)"
+// @Param page query int false "Page" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.BaseResponse{data=dto.PageResponse{items=[]audit.EventDTO}}
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	var query audit.EventListRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &query), "Invalid request")
+		return
+	}
+	query.Normalize()
+	h.listResponse(c, query.PageRequest, audit.Filter{
+		Type:      query.Type,
+		ActorID:   query.ActorID,
+		SubjectID: query.SubjectID,
+		IP:        query.IP,
+		From:      query.From,
+		To:        query.To,
+		Offset:    query.Offset(),
+		Limit:     query.PageSize,
+	})
+}
+
+// @Router /api/v1/auth/me/activity [get]
+// @Summary Own security activity
+// @Description Sign-ins, OTP codes, password and session changes on the current account.
+// @Tags auth
+// @Produce json
+// @Param type query string false "Event type"
+// @Param page query int false "Page" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.BaseResponse{data=dto.PageResponse{items=[]audit.EventDTO}}
+func (h *AuditHandler) Activity(c *gin.Context) {
+	var query audit.ActivityRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &query), "Invalid request")
+		return
+	}
+	user, err := h.auth.CurrentUser(c.Request.Context(), c.MustGet("user").(jwt.MapClaims))
+	if err != nil {
+		if status, ok := auth.AccountStatus(err); ok {
+			dto.JSON(c, http.StatusForbidden, status, err.Error())
+			return
+		}
+		dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+		return
+	}
+	query.Normalize()
+	h.listResponse(c, query.PageRequest, audit.Filter{
+		Type:      query.Type,
+		SubjectID: audit.ID(user.ID),
+		Offset:    query.Offset(),
+		Limit:     query.PageSize,
+	})
+}
+
+func (h *AuditHandler) listResponse(c *gin.Context, page dto.PageRequest, filter audit.Filter) {
+	events, total, err := h.usecase.List(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Error("audit list error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		return
+	}
+	items := make([]audit.EventDTO, 0, len(events))
+	for i := range events {
+		items = append(items, audit.ToEvent(&events[i]))
+	}
+	dto.JSON(c, http.StatusOK, dto.PageResponse{
+		Items:    items,
+		Total:    total,
+		Page:     page.Page,
+		PageSize: page.PageSize,
+	}, "")
+}
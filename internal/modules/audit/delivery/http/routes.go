@@ -0,0 +1,29 @@
+package http
+
+import (
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/middlewares"
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAuditRoutes(cfg *config.Config, router *gin.RouterGroup, h *AuditHandler) {
+	private := router.Group("")
+	private.Use(middlewares.AuthMiddleware(cfg, h.logger))
+	{
+		private.GET("/me/activity", h.Activity)
+	}
+	admin := router.Group("/admin")
+	admin.Use(
+		middlewares.AdminIPFilter(cfg),
+		middlewares.AuthMiddleware(cfg, h.logger),
+		middlewares.ActiveUser(h.auth),
+		middlewares.DenyImpersonation(),
+		middlewares.RequireRole(auth.RoleAdmin, auth.RoleSuper),
+		middlewares.RequireACR(auth.AcrMfa),
+		middlewares.RequirePermission(auth.PermAuditRead),
+	)
+	{
+		admin.GET("/audit", h.ListEvents)
+	}
+}
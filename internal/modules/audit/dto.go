@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/dto"
+)
+
+type EventDTO struct {
+	ID        uint            `json:"id"`
+	Type      string          `json:"type"`
+	ActorID   *uint           `json:"actor_id"`
+	SubjectID *uint           `json:"subject_id"`
+	IP        string          `json:"ip"`
+	UserAgent string          `json:"user_agent"`
+	Metadata  json.RawMessage `json:"metadata" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type EventListRequest struct {
+	dto.PageRequest
+	Type      string     `form:"type"`
+	ActorID   *uint      `form:"actor_id"`
+	SubjectID *uint      `form:"subject_id"`
+	IP        string     `form:"ip"`
+	From      *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type ActivityRequest struct {
+	dto.PageRequest
+	Type string `form:"type"`
+}
+
+func ToEvent(event *Event) EventDTO {
+	metadata := json.RawMessage(event.Metadata)
+	if len(metadata) == 0 {
+		metadata = json.RawMessage("{}")
+	}
+	return EventDTO{
+		ID:        event.ID,
+		Type:      event.Type,
+		ActorID:   event.ActorID,
+		SubjectID: event.SubjectID,
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+		Metadata:  metadata,
+		CreatedAt: event.CreatedAt,
+	}
+}
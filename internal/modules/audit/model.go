@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"gorm.io/gorm"
+)
+
+// Event xavfsizlikka oid bitta hodisa. Actor amalni bajargan, Subject esa amal
+// ta'sir qilgan foydalanuvchi; o'zi kirgan foydalanuvchida ikkalasi bir xil bo'ladi.
+// Autentifikatsiyasiz so'rovlarda Actor bo'sh.
+type Event struct {
+	gorm.Model
+	Type      string `gorm:"column:type;index"`
+	ActorID   *uint  `gorm:"column:actor_id;index"`
+	SubjectID *uint  `gorm:"column:subject_id;index"`
+	IP        string `gorm:"column:ip;index"`
+	UserAgent string `gorm:"column:user_agent"`
+	Metadata  string `gorm:"column:metadata;type:text"`
+}
+
+func (*Event) TableName() string {
+	return "audit_event"
+}
+
+const (
+	TypeLoginSuccess          = "login.success"
+	TypeLoginFailure          = "login.failure"
+	TypeRegister              = "register"
+	TypeOtpSent               = "otp.sent"
+	TypeOtpVerified           = "otp.verified"
+	TypePasswordChanged       = "password.changed"
+	TypeRoleChanged           = "role.changed"
+	TypeSessionsRevoked       = "sessions.revoked"
+	TypeUserActivated         = "admin.user.activated"
+	TypeUserDeactivated       = "admin.user.deactivated"
+	TypeUserSuspended         = "admin.user.suspended"
+	TypeUserUnsuspended       = "admin.user.unsuspended"
+	TypePasswordResetForced   = "admin.password_reset.forced"
+	TypeImpersonationStarted  = "admin.impersonation.started"
+	TypePermissionCreated     = "admin.permission.created"
+	TypePermissionDeleted     = "admin.permission.deleted"
+	TypeRolePermissionsChange = "admin.role.permissions_changed"
+	TypeAccountLocked         = "account.locked"
+	TypeUserUnlocked          = "admin.user.unlocked"
+	TypeOtpBlocked            = "otp.blocked"
+	TypeSmsAnomaly            = "sms.anomaly"
+	TypeSmsLimitsChanged      = "admin.sms.limits_changed"
+	TypeDeviceNew             = "device.new"
+	TypeDeviceRevoked         = "device.revoked"
+)
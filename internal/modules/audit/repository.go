@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Filter struct {
+	Type      string
+	ActorID   *uint
+	SubjectID *uint
+	IP        string
+	From      *time.Time
+	To        *time.Time
+	Offset    int
+	Limit     int
+}
+
+type AuditRepository interface {
+	Create(context.Context, *Event) error
+	List(context.Context, Filter) ([]Event, int64, error)
+}
+
+type AuditRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &AuditRepositoryImpl{
+		db: db,
+	}
+}
+
+func (a *AuditRepositoryImpl) Create(ctx context.Context, event *Event) error {
+	return a.db.WithContext(ctx).Create(event).Error
+}
+
+func (a *AuditRepositoryImpl) List(ctx context.Context, filter Filter) ([]Event, int64, error) {
+	query := a.db.WithContext(ctx).Model(&Event{})
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.SubjectID != nil {
+		query = query.Where("subject_id = ?", *filter.SubjectID)
+	}
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var events []Event
+	if err := query.Order("id desc").Offset(filter.Offset).Limit(filter.Limit).Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+)
+
+// Entry yoziladigan hodisa. ActorID berilmasa kontekstdagi token egasi olinadi,
+// autentifikatsiyasiz so'rovlarda (masalan begona odamning noto'g'ri paroli) bo'sh qoladi.
+type Entry struct {
+	Type      string
+	ActorID   *uint
+	SubjectID *uint
+	Metadata  map[string]any
+}
+
+type AuditUsecase interface {
+	Record(context.Context, Entry)
+	List(context.Context, Filter) ([]Event, int64, error)
+}
+
+type AuditUsecaseImpl struct {
+	repo   AuditRepository
+	logger *zap.Logger
+}
+
+func NewAuditUsecase(repo AuditRepository, logger *zap.Logger) AuditUsecase {
+	return &AuditUsecaseImpl{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Record hodisani yozadi. Audit yozuvidagi xato asosiy amalni to'xtatmaydi, faqat logga tushadi.
+func (a *AuditUsecaseImpl) Record(ctx context.Context, entry Entry) {
+	client := ClientFromContext(ctx)
+	actorID := entry.ActorID
+	if actorID == nil {
+		actorID = client.ActorID
+	}
+	event := &Event{
+		Type:      entry.Type,
+		ActorID:   actorID,
+		SubjectID: entry.SubjectID,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+	}
+	if len(entry.Metadata) > 0 {
+		metadata, err := json.Marshal(entry.Metadata)
+		if err != nil {
+			a.logger.Error("audit metadata error", zap.String("type", entry.Type), zap.Error(err))
+		} else {
+			event.Metadata = string(metadata)
+		}
+	}
+	if err := a.repo.Create(context.WithoutCancel(ctx), event); err != nil {
+		a.logger.Error("audit record error", zap.String("type", entry.Type), zap.Error(err))
+	}
+}
+
+func (a *AuditUsecaseImpl) List(ctx context.Context, filter Filter) ([]Event, int64, error) {
+	return a.repo.List(ctx, filter)
+}
+
+// ID ixtiyoriy ID maydonlar uchun yordamchi.
+func ID(id uint) *uint {
+	return &id
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/modules/audit"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// CurrentUser access token egasini yuklaydi. Sessiyalar bekor qilingandan oldin
+// berilgan va bekor qilingan qurilmaga tegishli tokenlar rad etiladi.
+func (a *AuthUsecaseImpl) CurrentUser(ctx context.Context, claims jwt.MapClaims) (*User, error) {
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	user, err := a.repo.GetID(ctx, int64(userID))
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if SessionRevoked(user, claims) || a.deviceRevoked(ctx, user.ID, AuthContextFromClaims(claims).SessionID) {
+		return nil, ErrSessionRevoked
+	}
+	if err := a.CheckStatus(user); err != nil {
+		return nil, err
+	}
+	// impersonation davomida admin o'chirilsa yoki bloklansa token ham ishlamay qoladi
+	if actorID, ok := ActorFromClaims(claims); ok {
+		actor, err := a.repo.GetID(ctx, int64(actorID))
+		if err != nil || a.CheckStatus(actor) != nil || SessionRevoked(actor, claims) {
+			return nil, ErrSessionRevoked
+		}
+	}
+	return user, nil
+}
+
+// CheckStatus o'chirilgan yoki muddatli bloklangan foydalanuvchiga token berilmasligi
+// uchun har bir kirish yo'lida chaqiriladi. Soft-delete qilinganlar repository da topilmaydi.
+func (a *AuthUsecaseImpl) CheckStatus(user *User) error {
+	if user.IsActive != nil && !*user.IsActive {
+		return ErrAccountDisabled
+	}
+	if user.IsSuspended() {
+		return &SuspendedError{Reason: user.SuspensionReason, Until: *user.SuspendedUntil}
+	}
+	return nil
+}
+
+func SessionRevoked(user *User, claims jwt.MapClaims) bool {
+	if user.SessionsRevokedAt == nil {
+		return false
+	}
+	iat, ok := claims["iat"].(float64)
+	return !ok || int64(iat) < user.SessionsRevokedAt.Unix()
+}
+
+func (a *AuthUsecaseImpl) ListUsers(ctx context.Context, filter UserFilter) ([]User, int64, error) {
+	return a.repo.ListUsers(ctx, filter)
+}
+
+// canManage admin o'zini va super foydalanuvchilarni boshqara olmaydi, super esa hammani.
+func canManage(actor *User, target *User) bool {
+	if actor.ID == target.ID {
+		return false
+	}
+	return target.Role != RoleSuper || actor.Role == RoleSuper
+}
+
+func (a *AuthUsecaseImpl) ChangeRole(ctx context.Context, actor *User, target *User, role Role) error {
+	if !canManage(actor, target) || (role == RoleSuper && actor.Role != RoleSuper) {
+		return ErrCannotManageUser
+	}
+	roles, err := a.ListRoles(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := roles[role]; !ok {
+		return ErrUnknownRole
+	}
+	previous := target.Role
+	a.repo.Update(ctx, target, map[string]any{"role": role})
+	target.Role = role
+	a.recordAdmin(ctx, audit.TypeRoleChanged, actor, target, map[string]any{"from": previous, "to": role})
+	return nil
+}
+
+// SetActive o'chirilgan foydalanuvchining sessiyalari ham bekor qilinadi.
+func (a *AuthUsecaseImpl) SetActive(ctx context.Context, actor *User, target *User, active bool) error {
+	if !canManage(actor, target) {
+		return ErrCannotManageUser
+	}
+	a.repo.Update(ctx, target, map[string]any{"is_active": active})
+	target.IsActive = &active
+	if !active {
+		a.RevokeSessions(ctx, target)
+	}
+	if active {
+		a.recordAdmin(ctx, audit.TypeUserActivated, actor, target, nil)
+	} else {
+		a.recordAdmin(ctx, audit.TypeUserDeactivated, actor, target, nil)
+	}
+	return nil
+}
+
+// Suspend foydalanuvchini until gacha bloklaydi va sessiyalarini bekor qiladi.
+func (a *AuthUsecaseImpl) Suspend(ctx context.Context, actor *User, target *User, reason string, until time.Time) error {
+	if !canManage(actor, target) {
+		return ErrCannotManageUser
+	}
+	if !until.After(time.Now()) {
+		return ErrInvalidSuspension
+	}
+	a.repo.Update(ctx, target, map[string]any{
+		"suspended_until":   until,
+		"suspension_reason": reason,
+	})
+	target.SuspendedUntil = &until
+	target.SuspensionReason = reason
+	a.RevokeSessions(ctx, target)
+	a.recordAdmin(ctx, audit.TypeUserSuspended, actor, target, map[string]any{"reason": reason, "until": until})
+	return nil
+}
+
+func (a *AuthUsecaseImpl) Unsuspend(ctx context.Context, actor *User, target *User) error {
+	if !canManage(actor, target) {
+		return ErrCannotManageUser
+	}
+	a.repo.Update(ctx, target, map[string]any{
+		"suspended_until":   nil,
+		"suspension_reason": "",
+	})
+	target.SuspendedUntil = nil
+	target.SuspensionReason = ""
+	a.recordAdmin(ctx, audit.TypeUserUnsuspended, actor, target, nil)
+	return nil
+}
+
+func (a *AuthUsecaseImpl) ForcePasswordReset(ctx context.Context, actor *User, target *User) error {
+	if !canManage(actor, target) {
+		return ErrCannotManageUser
+	}
+	a.repo.Update(ctx, target, map[string]any{"password_reset_required": true})
+	target.PasswordResetRequired = true
+	a.RevokeSessions(ctx, target)
+	a.recordAdmin(ctx, audit.TypePasswordResetForced, actor, target, nil)
+	return nil
+}
+
+// ImpersonationToken admin uchun target nomidan qisqa muddatli access token beradi.
+// act claimida admin ID si bo'ladi, auth_time yozilmaydi, shuning uchun RequireRecentAuth
+// bilan himoyalangan amallar ishlamaydi. Refresh token berilmaydi.
+func (a *AuthUsecaseImpl) ImpersonationToken(ctx context.Context, actor *User, target *User) (string, time.Time, error) {
+	if !canManage(actor, target) {
+		return "", time.Time{}, ErrCannotManageUser
+	}
+	if err := a.CheckStatus(target); err != nil {
+		return "", time.Time{}, err
+	}
+	exp := time.Now().Add(time.Minute * time.Duration(a.cfg.ImpersonationExp))
+	claims := jwt.MapClaims{
+		"user_id":     target.ID,
+		"exp":         exp.Unix(),
+		"iat":         time.Now().Unix(),
+		"token_type":  "access",
+		"jti":         utils.RandomString(20, "1234567890"),
+		"role":        target.Role,
+		"permissions": a.RolePermissions(ctx, target.Role),
+		"acr":         AcrBasic,
+		"act":         map[string]any{"sub": strconv.FormatUint(uint64(actor.ID), 10)},
+	}
+	token, err := utils.CreateJWT(claims, a.cfg.PrivateKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	a.recordAdmin(ctx, audit.TypeImpersonationStarted, actor, target, map[string]any{
+		"jti": claims["jti"],
+		"exp": exp,
+	})
+	return token, exp, nil
+}
+
+// ActorFromClaims impersonation tokenidagi act.sub ni qaytaradi.
+func ActorFromClaims(claims jwt.MapClaims) (uint, bool) {
+	act, ok := claims["act"].(map[string]any)
+	if !ok {
+		return 0, false
+	}
+	sub, _ := act["sub"].(string)
+	id, err := strconv.ParseUint(sub, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (a *AuthUsecaseImpl) RevokeSessions(ctx context.Context, user *User) {
+	now := time.Now()
+	a.repo.Update(ctx, user, map[string]any{"sessions_revoked_at": now})
+	user.SessionsRevokedAt = &now
+	a.record(ctx, audit.TypeSessionsRevoked, user, nil)
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/JscorpTech/auth/internal/modules/audit"
+)
+
+// record xavfsizlik hodisasini audit jurnaliga yozadi. subject noma'lum bo'lsa nil beriladi,
+// actor esa so'rov kontekstidan olinadi.
+func (a *AuthUsecaseImpl) record(ctx context.Context, eventType string, subject *User, metadata map[string]any) {
+	entry := audit.Entry{Type: eventType, Metadata: metadata}
+	if subject != nil {
+		entry.SubjectID = audit.ID(subject.ID)
+	}
+	a.audit.Record(ctx, entry)
+}
+
+// recordAdmin admin amallari uchun, actor aniq beriladi.
+func (a *AuthUsecaseImpl) recordAdmin(ctx context.Context, eventType string, actor *User, target *User, metadata map[string]any) {
+	a.audit.Record(ctx, audit.Entry{
+		Type:      eventType,
+		ActorID:   audit.ID(actor.ID),
+		SubjectID: audit.ID(target.ID),
+		Metadata:  metadata,
+	})
+}
+
+// LoginSucceeded token juftligi berilishidan oldin chaqiriladi, qurilmani aniqlab
+// authCtx.SessionID ni o'rnatadi. 2FA kutilayotgan bosqich muvaffaqiyatli kirish hisoblanmaydi.
+// Shu nuqtada foydalanuvchi o'zini isbotlagan, shuning uchun u actor sifatida yoziladi.
+func (a *AuthUsecaseImpl) LoginSucceeded(ctx context.Context, user *User, authCtx *AuthContext) {
+	ctx = audit.WithActor(ctx, user.ID)
+	a.trackDevice(ctx, user, authCtx)
+	a.record(ctx, audit.TypeLoginSuccess, user, map[string]any{
+		"acr": authCtx.Acr,
+		"amr": authCtx.Amr,
+	})
+}
+
+// LoginFailed user noma'lum bo'lsa nil bo'ladi.
+func (a *AuthUsecaseImpl) LoginFailed(ctx context.Context, user *User, method string, err error) {
+	a.record(ctx, audit.TypeLoginFailure, user, map[string]any{
+		"method": method,
+		"reason": err.Error(),
+	})
+}
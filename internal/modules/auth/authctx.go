@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// amr qiymatlari RFC 8176 bo'yicha, registrda yo'qlari (fed, email) ham shu uslubda.
+const (
+	AmrPassword    = "pwd"
+	AmrOtp         = "otp"
+	AmrSms         = "sms"
+	AmrHardwareKey = "hwk"
+	AmrUser        = "user"
+	AmrFederated   = "fed"
+	AmrEmail       = "email"
+	AmrMfa         = "mfa"
+)
+
+const (
+	AcrBasic = "basic"
+	AcrMfa   = "mfa"
+)
+
+var acrLevels = map[string]int{
+	AcrBasic: 1,
+	AcrMfa:   2,
+}
+
+// AuthContext foydalanuvchi qachon va qanday usulda autentifikatsiyadan o'tganini
+// saqlaydi. Access va refresh tokenlarga auth_time, amr va acr claimlari sifatida yoziladi,
+// refresh paytida o'zgarmasdan ko'chiriladi.
+type AuthContext struct {
+	AuthTime time.Time
+	Amr      []string
+	Acr      string
+	// SessionID kirilgan qurilma (Device) ID si, tokenlarga sid claim i sifatida yoziladi.
+	SessionID uint
+}
+
+func NewAuthContext(acr string, amr ...string) *AuthContext {
+	return &AuthContext{
+		AuthTime: time.Now(),
+		Amr:      amr,
+		Acr:      acr,
+	}
+}
+
+// WithSecondFactor birinchi faktorga ikkinchisini qo'shib, acr ni mfa ga ko'taradi.
+func (a *AuthContext) WithSecondFactor(amr string) *AuthContext {
+	methods := slices.Clone(a.Amr)
+	if !slices.Contains(methods, amr) {
+		methods = append(methods, amr)
+	}
+	if !slices.Contains(methods, AmrMfa) {
+		methods = append(methods, AmrMfa)
+	}
+	return &AuthContext{
+		AuthTime:  time.Now(),
+		Amr:       methods,
+		Acr:       AcrMfa,
+		SessionID: a.SessionID,
+	}
+}
+
+func (a *AuthContext) apply(claims jwt.MapClaims) {
+	claims["auth_time"] = a.AuthTime.Unix()
+	claims["amr"] = a.Amr
+	claims["acr"] = a.Acr
+	if a.SessionID != 0 {
+		claims["sid"] = a.SessionID
+	}
+}
+
+func AuthContextFromClaims(claims jwt.MapClaims) *AuthContext {
+	authCtx := &AuthContext{Acr: AcrBasic}
+	if authTime, ok := claims["auth_time"].(float64); ok {
+		authCtx.AuthTime = time.Unix(int64(authTime), 0)
+	}
+	if amr, ok := claims["amr"].([]any); ok {
+		for _, method := range amr {
+			if value, ok := method.(string); ok {
+				authCtx.Amr = append(authCtx.Amr, value)
+			}
+		}
+	}
+	if acr, ok := claims["acr"].(string); ok {
+		authCtx.Acr = acr
+	}
+	if sid, ok := claims["sid"].(float64); ok {
+		authCtx.SessionID = uint(sid)
+	}
+	return authCtx
+}
+
+// AcrSatisfies berilgan acr talab qilinganidan past emasligini tekshiradi.
+func AcrSatisfies(acr string, required string) bool {
+	level, ok := acrLevels[acr]
+	return ok && level >= acrLevels[required]
+}
@@ -0,0 +1,259 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/JscorpTech/auth/internal/dto"
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @Router /api/v1/auth/admin/users [get]
+// @Summary List users
+// @Tags admin
+// @Produce json
+// @Param search query string false "Phone, email or name"
+// @Param role query string false "Role"
+// @Param is_active query bool false "Active"
+// @Param confirmed query bool false "Phone confirmed"
+// @Param page query int false "Page" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.BaseResponse{data=dto.PageResponse{items=[]auth.AdminUserDTO}}
+func (h *AuthHandler) ListUsers(c *gin.Context) {
+	var query auth.AdminUserListRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &query), "Invalid request")
+		return
+	}
+	query.Normalize()
+	users, total, err := h.usecase.ListUsers(c.Request.Context(), auth.UserFilter{
+		Search:    query.Search,
+		Role:      query.Role,
+		IsActive:  query.IsActive,
+		Confirmed: query.Confirmed,
+		Offset:    query.Offset(),
+		Limit:     query.PageSize,
+	})
+	if err != nil {
+		h.adminError(c, err)
+		return
+	}
+	items := make([]auth.AdminUserDTO, 0, len(users))
+	for i := range users {
+		items = append(items, auth.ToAdminUser(&users[i]))
+	}
+	dto.JSON(c, http.StatusOK, dto.PageResponse{
+		Items:    items,
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}, "")
+}
+
+// @Router /api/v1/auth/admin/users/{id} [get]
+// @Summary Get user
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.BaseResponse{data=auth.AdminUserDTO}
+func (h *AuthHandler) GetUser(c *gin.Context) {
+	target, ok := h.targetUser(c)
+	if !ok {
+		return
+	}
+	dto.JSON(c, http.StatusOK, auth.ToAdminUser(target), "")
+}
+
+// @Router /api/v1/auth/admin/users/{id}/role [put]
+// @Summary Change user role
+// @Description Takes effect with the next access token. Only super users can grant the super role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body auth.AdminRoleRequest true "Role"
+// @Success 200 {object} dto.BaseResponse{data=auth.AdminUserDTO}
+func (h *AuthHandler) ChangeRole(c *gin.Context) {
+	var payload auth.AdminRoleRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	h.adminAction(c, func(actor *auth.User, target *auth.User) error {
+		return h.usecase.ChangeRole(c.Request.Context(), actor, target, payload.Role)
+	})
+}
+
+// @Router /api/v1/auth/admin/users/{id}/activate [post]
+// @Summary Activate user
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.BaseResponse{data=auth.AdminUserDTO}
+func (h *AuthHandler) ActivateUser(c *gin.Context) {
+	h.adminAction(c, func(actor *auth.User, target *auth.User) error {
+		return h.usecase.SetActive(c.Request.Context(), actor, target, true)
+	})
+}
+
+// @Router /api/v1/auth/admin/users/{id}/deactivate [post]
+// @Summary Deactivate user
+// @Description Also revokes all sessions of the user.
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.BaseResponse{data=auth.AdminUserDTO}
+func (h *AuthHandler) DeactivateUser(c *gin.Context) {
+	h.adminAction(c, func(actor *auth.User, target *auth.User) error {
+		return h.usecase.SetActive(c.Request.Context(), actor, target, false)
+	})
+}
+
+// @Router /api/v1/auth/admin/users/{id}/suspend [post]
+// @Summary Suspend user until a given time
+// @Description Login attempts return 403 with code account_suspended, reason and end time. Revokes all sessions.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body auth.SuspendRequest true "Suspension"
+// @Success 200 {object} dto.BaseResponse{data=auth.AdminUserDTO}
+func (h *AuthHandler) SuspendUser(c *gin.Context) {
+	var payload auth.SuspendRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	h.adminAction(c, func(actor *auth.User, target *auth.User) error {
+		return h.usecase.Suspend(c.Request.Context(), actor, target, payload.Reason, payload.Until)
+	})
+}
+
+// @Router /api/v1/auth/admin/users/{id}/unsuspend [post]
+// @Summary Lift user suspension
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.BaseResponse{data=auth.AdminUserDTO}
+func (h *AuthHandler) UnsuspendUser(c *gin.Context) {
+	h.adminAction(c, func(actor *auth.User, target *auth.User) error {
+		return h.usecase.Unsuspend(c.Request.Context(), actor, target)
+	})
+}
+
+// @Router /api/v1/auth/admin/users/{id}/unlock [post]
+// @Summary Clear failed login lockout
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.BaseResponse{data=auth.AdminUserDTO}
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	h.adminAction(c, func(actor *auth.User, target *auth.User) error {
+		return h.usecase.Unlock(c.Request.Context(), actor, target)
+	})
+}
+
+// @Router /api/v1/auth/admin/users/{id}/impersonate [post]
+// @Summary Get an access token acting as the user
+// @Description Short-lived access token with an act claim naming the admin. No refresh token is issued.
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.BaseResponse{data=auth.ImpersonationResponse}
+func (h *AuthHandler) Impersonate(c *gin.Context) {
+	actor, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	target, ok := h.targetUser(c)
+	if !ok {
+		return
+	}
+	token, exp, err := h.usecase.ImpersonationToken(c.Request.Context(), actor, target)
+	if err != nil {
+		if h.accountError(c, err) {
+			return
+		}
+		h.adminError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, auth.ImpersonationResponse{
+		Access:    token,
+		ExpiresAt: exp,
+		User:      auth.ToUser(target),
+	}, "")
+}
+
+// @Router /api/v1/auth/admin/users/{id}/password-reset [post]
+// @Summary Force password reset
+// @Description Blocks password login until the user resets it via /password/reset and revokes all sessions.
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.BaseResponse{data=auth.AdminUserDTO}
+func (h *AuthHandler) ForcePasswordReset(c *gin.Context) {
+	h.adminAction(c, func(actor *auth.User, target *auth.User) error {
+		return h.usecase.ForcePasswordReset(c.Request.Context(), actor, target)
+	})
+}
+
+// @Router /api/v1/auth/admin/users/{id}/revoke-sessions [post]
+// @Summary Revoke all sessions
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.BaseResponse{data=auth.AdminUserDTO}
+func (h *AuthHandler) RevokeSessions(c *gin.Context) {
+	h.adminAction(c, func(actor *auth.User, target *auth.User) error {
+		h.usecase.RevokeSessions(c.Request.Context(), target)
+		h.logger.Info("sessions revoked", zap.Uint("actor_id", actor.ID), zap.Uint("user_id", target.ID))
+		return nil
+	})
+}
+
+func (h *AuthHandler) targetUser(c *gin.Context) (*auth.User, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		dto.JSON(c, http.StatusBadRequest, nil, "Invalid user id")
+		return nil, false
+	}
+	user, err := h.usecase.GetUserByID(c.Request.Context(), id)
+	if err != nil {
+		dto.JSON(c, http.StatusNotFound, nil, auth.ErrUserNotFound.Error())
+		return nil, false
+	}
+	return user, true
+}
+
+func (h *AuthHandler) adminAction(c *gin.Context, action func(actor *auth.User, target *auth.User) error) {
+	actor, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	target, ok := h.targetUser(c)
+	if !ok {
+		return
+	}
+	if err := action(actor, target); err != nil {
+		h.adminError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, auth.ToAdminUser(target), "")
+}
+
+func (h *AuthHandler) adminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auth.ErrCannotManageUser):
+		dto.JSON(c, http.StatusForbidden, nil, err.Error())
+	case errors.Is(err, auth.ErrUnknownRole),
+		errors.Is(err, auth.ErrInvalidSuspension):
+		dto.JSON(c, http.StatusBadRequest, nil, err.Error())
+	default:
+		h.logger.Error("admin error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+	}
+}
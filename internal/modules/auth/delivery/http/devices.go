@@ -0,0 +1,101 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/JscorpTech/auth/internal/dto"
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// sessionID joriy token qaysi qurilmaga tegishli, qayta berilgan tokenlar ham shu
+// qurilmaga bog'lanib qolishi uchun.
+func sessionID(c *gin.Context) uint {
+	return auth.AuthContextFromClaims(c.MustGet("user").(jwt.MapClaims)).SessionID
+}
+
+// @Router /api/v1/auth/me/devices [get]
+// @Summary Known devices
+// @Description Devices the account has logged in from. current marks the device of this token.
+// @Tags devices
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]auth.DeviceDTO}
+func (h *AuthHandler) Devices(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	devices, err := h.usecase.ListDevices(c.Request.Context(), user)
+	if err != nil {
+		h.deviceError(c, err)
+		return
+	}
+	current := sessionID(c)
+	data := make([]auth.DeviceDTO, 0, len(devices))
+	for i := range devices {
+		data = append(data, auth.ToDevice(&devices[i], current))
+	}
+	dto.JSON(c, http.StatusOK, data, "")
+}
+
+// @Router /api/v1/auth/me/devices/{id} [delete]
+// @Summary Revoke device session
+// @Description Tokens issued to the device stop working. Logging in from it again creates a new device.
+// @Tags devices
+// @Produce json
+// @Param id path int true "Device ID"
+// @Success 200 {object} dto.BaseResponse
+func (h *AuthHandler) RevokeDevice(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		dto.JSON(c, http.StatusBadRequest, nil, "Invalid device id")
+		return
+	}
+	if err := h.usecase.RevokeDevice(c.Request.Context(), user, uint(id)); err != nil {
+		h.deviceError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, nil, "")
+}
+
+// @Router /api/v1/auth/devices/revoke [post]
+// @Summary Revoke device session from alert link
+// @Description Token comes from the link in the new device alert, no login required.
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Param request body auth.RevokeDeviceRequest true "Revoke token"
+// @Success 200 {object} dto.BaseResponse
+func (h *AuthHandler) RevokeDeviceByToken(c *gin.Context) {
+	var payload auth.RevokeDeviceRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	if err := h.usecase.RevokeDeviceByToken(c.Request.Context(), payload.Token); err != nil {
+		h.deviceError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, nil, "")
+}
+
+func (h *AuthHandler) deviceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auth.ErrDeviceNotFound):
+		dto.JSON(c, http.StatusNotFound, nil, err.Error())
+	case errors.Is(err, auth.ErrInvalidDeviceToken):
+		dto.JSON(c, http.StatusBadRequest, nil, err.Error())
+	default:
+		h.logger.Error("device error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+	}
+}
@@ -1,16 +1,20 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/JscorpTech/auth/internal/dto"
 	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/JscorpTech/auth/internal/modules/smsguard"
+	"github.com/JscorpTech/auth/internal/password"
+	"github.com/JscorpTech/auth/internal/phone"
+	"github.com/JscorpTech/auth/internal/social"
 	"github.com/JscorpTech/auth/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
-	"gorm.io/gorm"
 )
 
 type AuthHandler struct {
@@ -25,29 +29,41 @@ func NewAuthHandler(usecase auth.AuthUsecase, logger *zap.Logger) *AuthHandler {
 	}
 }
 
-// @Router /api/v1/auth/google [post]
+// @Router /api/v1/auth/social/{provider} [post]
 // @Accept json
 // @Produce json
 // @Tags auth
-// @Summary Google authentication
-// @Param request body auth.GoogleAuthRequest true "Google auth request"
+// @Summary Social authentication
+// @Param provider path string true "Provider name (google, apple, github, ...)"
+// @Param request body auth.SocialAuthRequest true "Social auth request"
 // @Success 200 {object} dto.BaseResponse{data=auth.AuthLoginResponse}
-func (h *AuthHandler) Google(c *gin.Context) {
-	var payload auth.GoogleAuthRequest
+// @Success 202 {object} dto.BaseResponse{data=auth.AuthMfaChallengeResponse}
+func (h *AuthHandler) Social(c *gin.Context) {
+	var payload auth.SocialAuthRequest
 	ctx := c.Request.Context()
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
 		return
 	}
-	user, err := h.usecase.GoogleAuth(ctx, payload.IDToken)
+	user, err := h.usecase.SocialAuth(ctx, c.Param("provider"), payload.Token)
 	if err != nil {
-		dto.JSON(c, http.StatusUnauthorized, nil, "Invalid Google ID token")
+		if h.accountError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, social.ErrUnknownProvider):
+			dto.JSON(c, http.StatusNotFound, nil, err.Error())
+		case errors.Is(err, social.ErrInvalidToken),
+			errors.Is(err, auth.ErrEmailNotProvided),
+			errors.Is(err, auth.ErrEmailNotVerified):
+			dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+		default:
+			h.logger.Error("social auth error", zap.Error(err))
+			dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		}
 		return
 	}
-	dto.JSON(c, http.StatusOK, auth.AuthLoginResponse{
-		Token: auth.ToToken(h.usecase.AccessToken(user), h.usecase.RefreshToken(user)),
-		User:  auth.ToUser(user),
-	}, "")
+	h.loginResponse(c, user, auth.NewAuthContext(auth.AcrBasic, auth.AmrFederated))
 }
 
 // @Router /api/v1/auth/refresh [post]
@@ -63,20 +79,15 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
 		return
 	}
-	claims, err := h.usecase.ValidateToken(payload.RefreshToken)
+	user, authCtx, err := h.usecase.Refresh(c.Request.Context(), payload.RefreshToken)
 	if err != nil {
+		if h.accountError(c, err) {
+			return
+		}
 		dto.JSON(c, http.StatusUnauthorized, nil, "Invalid refresh token")
 		return
 	}
-
-	user := &auth.User{
-		Model: gorm.Model{
-			ID: uint((*claims)["user_id"].(float64)),
-		},
-		Role: (*claims)["role"].(auth.Role),
-	}
-
-	dto.JSON(c, http.StatusOK, auth.ToToken(h.usecase.AccessToken(user), ""), "")
+	dto.JSON(c, http.StatusOK, auth.ToToken(h.usecase.AccessToken(user, authCtx), ""), "")
 }
 
 // Register godoc
@@ -86,6 +97,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} dto.BaseResponse{data=auth.AuthLoginResponse}
+// @Success 202 {object} dto.BaseResponse{data=auth.AuthMfaChallengeResponse}
 // @Param request body auth.AuthLoginRequest true "Login form"
 func (h *AuthHandler) Login(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -96,13 +108,74 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 	user, err := h.usecase.Login(ctx, payload.Phone, payload.Password)
 	if err != nil {
+		if h.accountError(c, err) || h.phoneError(c, err) {
+			return
+		}
 		dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
 		return
 	}
-	dto.JSON(c, http.StatusOK, auth.AuthLoginResponse{
-		Token: auth.ToToken(h.usecase.AccessToken(user), h.usecase.RefreshToken(user)),
-		User:  auth.ToUser(user),
-	}, "")
+	h.loginResponse(c, user, auth.NewAuthContext(auth.AcrBasic, auth.AmrPassword))
+}
+
+// phoneError noto'g'ri raqamni boshqa xatolardan ajratib phone maydoni xatosi sifatida qaytaradi.
+func (h *AuthHandler) phoneError(c *gin.Context, err error) bool {
+	if !errors.Is(err, phone.ErrInvalidPhone) {
+		return false
+	}
+	dto.JSON(c, http.StatusBadRequest, map[string]string{"phone": "Invalid phone number"}, "Invalid request")
+	return true
+}
+
+// otpError kod yuborishdagi xatolar: limitlar 429, SMS yuborish taqiqlangan raqam 403,
+// mijoz uni noto'g'ri so'rovdan ajrata olishi uchun.
+func (h *AuthHandler) otpError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, auth.ErrRateLimit), errors.Is(err, smsguard.ErrDailyLimit):
+		dto.JSON(c, http.StatusTooManyRequests, nil, err.Error())
+	case errors.Is(err, smsguard.ErrNumberNotAllowed):
+		dto.JSON(c, http.StatusForbidden, nil, "SMS cannot be sent to this phone number")
+	default:
+		return false
+	}
+	return true
+}
+
+// accountError o'chirilgan yoki bloklangan akkaunt xatosini alohida kod bilan 403 qilib
+// qaytaradi, mijoz uni noto'g'ri parol yoki tokendan ajrata olishi uchun.
+func (h *AuthHandler) accountError(c *gin.Context, err error) bool {
+	status, ok := auth.AccountStatus(err)
+	if ok {
+		dto.JSON(c, http.StatusForbidden, status, err.Error())
+	}
+	return ok
+}
+
+// loginResponse 2FA yoqilgan foydalanuvchiga token juftligi o'rniga qisqa muddatli
+// mfa_token qaytaradi, u /login/mfa orqali almashtiriladi.
+func (h *AuthHandler) loginResponse(c *gin.Context, user *auth.User, authCtx *auth.AuthContext) {
+	tokens, challenge := h.usecase.CompleteLogin(c.Request.Context(), user, authCtx)
+	if challenge != nil {
+		dto.JSON(c, http.StatusAccepted, challenge, "")
+		return
+	}
+	dto.JSON(c, http.StatusOK, tokens, "")
+}
+
+// currentUser AuthMiddleware qo'ygan claimlar bo'yicha foydalanuvchini yuklaydi, ActiveUser
+// allaqachon yuklagan bo'lsa o'shani oladi. Xatolik bo'lsa javob yozilgan bo'ladi va false qaytadi.
+func (h *AuthHandler) currentUser(c *gin.Context) (*auth.User, bool) {
+	if user, ok := c.Get("current_user"); ok {
+		return user.(*auth.User), true
+	}
+	user, err := h.usecase.CurrentUser(c.Request.Context(), c.MustGet("user").(jwt.MapClaims))
+	if err != nil {
+		if h.accountError(c, err) {
+			return nil, false
+		}
+		dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+		return nil, false
+	}
+	return user, true
 }
 
 // Register godoc
@@ -113,23 +186,140 @@ func (h *AuthHandler) Login(c *gin.Context) {
 // @Produce json
 // @Success 200 {object} dto.BaseResponse{data=auth.AuthMeResponse}
 func (h *AuthHandler) Me(c *gin.Context) {
-	claims := c.MustGet("user").(jwt.MapClaims)
-	ctx := c.Request.Context()
-	userID, ok := claims["user_id"]
+	user, ok := h.currentUser(c)
 	if !ok {
-		dto.JSON(c, http.StatusInternalServerError, nil, "User id not found")
-		return
-	}
-	user, err := h.usecase.GetUserByID(ctx, int64(userID.(float64)))
-	if err != nil {
-		dto.JSON(c, http.StatusBadRequest, nil, "User not found")
 		return
 	}
+	ctx := c.Request.Context()
 	dto.JSON(c, http.StatusOK, auth.AuthMeResponse{
 		User: auth.ToUser(user),
+		Mfa: auth.MfaStatusDTO{
+			Enabled:                h.usecase.MfaEnabled(ctx, user),
+			Methods:                h.usecase.MfaMethods(ctx, user),
+			RecoveryCodesRemaining: h.usecase.RecoveryCodesRemaining(ctx, user),
+		},
 	}, "")
 }
 
+// @Router /api/v1/auth/password/reset [post]
+// @Summary Request password reset code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body auth.PasswordResetRequest true "Phone"
+// @Param X-Captcha-Token header string false "Captcha token, required when the response code is captcha_required"
+// @Success 200 {object} dto.BaseResponse
+func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
+	var payload auth.PasswordResetRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	if err := h.usecase.RequestPasswordReset(c.Request.Context(), payload.Phone); err != nil {
+		if h.phoneError(c, err) || h.otpError(c, err) {
+			return
+		}
+		h.logger.Error("password reset error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		return
+	}
+	dto.JSON(c, http.StatusOK, nil, "")
+}
+
+// @Router /api/v1/auth/password/reset/confirm [post]
+// @Summary Set new password with reset code
+// @Description Revokes all existing sessions.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body auth.PasswordResetConfirmRequest true "Reset"
+// @Success 200 {object} dto.BaseResponse
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var payload auth.PasswordResetConfirmRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	if err := h.usecase.ResetPassword(c.Request.Context(), payload.Phone, payload.Otp, payload.Password); err != nil {
+		if errors.Is(err, auth.ErrInvalidOtp) {
+			dto.JSON(c, http.StatusForbidden, nil, err.Error())
+			return
+		}
+		if errors.Is(err, password.ErrPolicy) {
+			dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+			return
+		}
+		h.logger.Error("password reset error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		return
+	}
+	dto.JSON(c, http.StatusOK, nil, "")
+}
+
+// @Router /api/v1/auth/password/change [post]
+// @Summary Change password
+// @Description Revokes all existing sessions and returns a new token pair for the current device.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body auth.PasswordChangeRequest true "Change"
+// @Success 200 {object} dto.BaseResponse{data=auth.TokenDTO}
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var payload auth.PasswordChangeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	if err := h.usecase.ChangePassword(ctx, user, payload.CurrentPassword, payload.Password); err != nil {
+		if h.accountError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, auth.ErrInvalidPassword):
+			dto.JSON(c, http.StatusForbidden, nil, err.Error())
+		case errors.Is(err, password.ErrPolicy):
+			dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		default:
+			h.logger.Error("password change error", zap.Error(err))
+			dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		}
+		return
+	}
+	authCtx := auth.NewAuthContext(auth.AcrBasic, auth.AmrPassword)
+	authCtx.SessionID = sessionID(c)
+	dto.JSON(c, http.StatusOK, auth.ToToken(h.usecase.AccessToken(user, authCtx), h.usecase.RefreshToken(user, authCtx)), "")
+}
+
+// @Router /api/v1/auth/me/otp-channel [put]
+// @Summary Set OTP delivery channel
+// @Description Telegram requires the phone to be shared with the bot, otherwise codes fall back to SMS.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body auth.OtpChannelRequest true "Channel"
+// @Success 200 {object} dto.BaseResponse{data=auth.UserDTO}
+func (h *AuthHandler) SetOtpChannel(c *gin.Context) {
+	var payload auth.OtpChannelRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if err := h.usecase.SetOtpChannel(c.Request.Context(), user, payload.Channel); err != nil {
+		dto.JSON(c, http.StatusBadRequest, nil, err.Error())
+		return
+	}
+	dto.JSON(c, http.StatusOK, auth.ToUser(user), "")
+}
+
 // Register godoc
 // @Summary Register user
 // @Description Create new user
@@ -139,6 +329,7 @@ func (h *AuthHandler) Me(c *gin.Context) {
 // @Router /api/v1/auth/register [post]
 // @Success 200 {object} dto.BaseResponse{data=auth.AuthRegisterResponse}
 // @Param request body auth.AuthRegisterRequest true "Register request"
+// @Param X-Captcha-Token header string false "Captcha token, required when the response code is captcha_required"
 func (h *AuthHandler) Register(c *gin.Context) {
 	ctx := c.Request.Context()
 	var userPayload auth.AuthRegisterRequest
@@ -146,11 +337,6 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &userPayload), "Invalid request")
 		return
 	}
-	password, err := utils.HashPassword(userPayload.Password)
-	if err != nil {
-		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
-		return
-	}
 	isSuperUser := false
 	isStaff := false
 	isActive := true
@@ -163,10 +349,27 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		IsStaff:     &isStaff,
 		IsActive:    &isActive,
 		DateJoined:  &dateJoined,
-		Password:    password,
 	}
+	if err := h.usecase.ValidatePassword(userPayload.Password, &userModel); err != nil {
+		if errors.Is(err, password.ErrPolicy) {
+			dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &userPayload), "Invalid request")
+			return
+		}
+		h.logger.Error("password policy error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		return
+	}
+	hash, err := h.usecase.HashPassword(userPayload.Password)
+	if err != nil {
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		return
+	}
+	userModel.Password = hash
 	user, err := h.usecase.Register(ctx, &userModel)
 	if err != nil {
+		if h.phoneError(c, err) || h.otpError(c, err) {
+			return
+		}
 		dto.JSON(c, http.StatusBadRequest, nil, err.Error())
 		return
 	}
@@ -181,9 +384,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Summary Confirm phone number
 // @Accept json
 // @Produce json
+// @Description For accounts with 2FA an mfa_token is returned instead of tokens, the SMS code alone does not sign in.
 // @Param request body auth.AuthConfirmRequest true "Confirm request"
 // @Tags auth
-// @Success 200 {object} dto.BaseResponse{data=auth.TokenDTO}
+// @Success 200 {object} dto.BaseResponse{data=auth.AuthLoginResponse}
+// @Success 202 {object} dto.BaseResponse{data=auth.AuthMfaChallengeResponse}
 func (h *AuthHandler) Confirm(c *gin.Context) {
 	var payload auth.AuthConfirmRequest
 	ctx := c.Request.Context()
@@ -200,6 +405,10 @@ func (h *AuthHandler) Confirm(c *gin.Context) {
 		dto.JSON(c, http.StatusBadRequest, nil, "Invalid phone number")
 		return
 	}
+	if err := h.usecase.CheckStatus(user); err != nil {
+		h.accountError(c, err)
+		return
+	}
 	h.usecase.Confirm(ctx, user)
-	dto.JSON(c, 200, auth.ToToken(h.usecase.AccessToken(user), h.usecase.RefreshToken(user)), "")
+	h.loginResponse(c, user, auth.NewAuthContext(auth.AcrBasic, auth.AmrSms))
 }
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/JscorpTech/auth/internal/dto"
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @Router /api/v1/auth/magic-link [post]
+// @Summary Send magic login link
+// @Description Always succeeds so that registered emails can not be enumerated
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body auth.MagicLinkRequest true "Magic link request"
+// @Success 200 {object} dto.BaseResponse
+func (h *AuthHandler) MagicLink(c *gin.Context) {
+	var payload auth.MagicLinkRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	if err := h.usecase.SendMagicLink(c.Request.Context(), payload.Email, payload.DeviceID); err != nil {
+		h.logger.Error("send magic link error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		return
+	}
+	dto.JSON(c, http.StatusOK, nil, "")
+}
+
+// @Router /api/v1/auth/magic-link/verify [post]
+// @Summary Log in with magic link token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body auth.MagicLinkVerifyRequest true "Magic link verify request"
+// @Success 200 {object} dto.BaseResponse{data=auth.AuthLoginResponse}
+// @Success 202 {object} dto.BaseResponse{data=auth.AuthMfaChallengeResponse}
+func (h *AuthHandler) VerifyMagicLink(c *gin.Context) {
+	var payload auth.MagicLinkVerifyRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	user, err := h.usecase.VerifyMagicLink(c.Request.Context(), payload.Token, payload.DeviceID)
+	if err != nil {
+		if h.accountError(c, err) {
+			return
+		}
+		dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+		return
+	}
+	h.loginResponse(c, user, auth.NewAuthContext(auth.AcrBasic, auth.AmrEmail))
+}
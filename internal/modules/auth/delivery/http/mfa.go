@@ -0,0 +1,286 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JscorpTech/auth/internal/dto"
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// @Router /api/v1/auth/login/mfa [post]
+// @Summary Complete login with second factor
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body auth.AuthLoginMfaRequest true "MFA login request"
+// @Success 200 {object} dto.BaseResponse{data=auth.AuthLoginResponse}
+func (h *AuthHandler) LoginMfa(c *gin.Context) {
+	var payload auth.AuthLoginMfaRequest
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	user, authCtx, err := h.usecase.ValidateMfaToken(ctx, payload.MfaToken)
+	if err != nil {
+		if h.accountError(c, err) {
+			return
+		}
+		dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+		return
+	}
+	if err := h.usecase.VerifySecondFactor(ctx, user, payload.Code); err != nil {
+		h.usecase.LoginFailed(ctx, user, auth.AmrOtp, err)
+		if h.accountError(c, err) {
+			return
+		}
+		dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+		return
+	}
+	authCtx = authCtx.WithSecondFactor(auth.AmrOtp)
+	h.usecase.LoginSucceeded(ctx, user, authCtx)
+	dto.JSON(c, http.StatusOK, auth.AuthLoginResponse{
+		Token: auth.ToToken(h.usecase.AccessToken(user, authCtx), h.usecase.RefreshToken(user, authCtx)),
+		User:  auth.ToUser(user),
+	}, "")
+}
+
+// @Router /api/v1/auth/login/mfa/enroll [post]
+// @Summary Start TOTP enrollment during login
+// @Description For admin and staff accounts without 2FA (setup_required in the login response).
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body auth.AuthMfaEnrollRequest true "MFA token"
+// @Success 200 {object} dto.BaseResponse{data=auth.TotpEnrollResponse}
+func (h *AuthHandler) LoginMfaEnroll(c *gin.Context) {
+	var payload auth.AuthMfaEnrollRequest
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	user, _, err := h.usecase.ValidateMfaToken(ctx, payload.MfaToken)
+	if err != nil {
+		if h.accountError(c, err) {
+			return
+		}
+		dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+		return
+	}
+	secret, uri, err := h.usecase.EnrollTotp(ctx, user)
+	if err != nil {
+		h.mfaError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, auth.TotpEnrollResponse{Secret: secret, URI: uri}, "")
+}
+
+// @Router /api/v1/auth/login/mfa/enroll/confirm [post]
+// @Summary Confirm TOTP enrollment and complete login
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body auth.AuthMfaEnrollConfirmRequest true "MFA token and TOTP code"
+// @Success 200 {object} dto.BaseResponse{data=auth.AuthMfaEnrollConfirmResponse}
+func (h *AuthHandler) LoginMfaEnrollConfirm(c *gin.Context) {
+	var payload auth.AuthMfaEnrollConfirmRequest
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	user, authCtx, err := h.usecase.ValidateMfaToken(ctx, payload.MfaToken)
+	if err != nil {
+		if h.accountError(c, err) {
+			return
+		}
+		dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+		return
+	}
+	codes, err := h.usecase.ConfirmTotp(ctx, user, payload.Code)
+	if err != nil {
+		h.mfaError(c, err)
+		return
+	}
+	authCtx = authCtx.WithSecondFactor(auth.AmrOtp)
+	h.usecase.LoginSucceeded(ctx, user, authCtx)
+	dto.JSON(c, http.StatusOK, auth.AuthMfaEnrollConfirmResponse{
+		Token:         auth.ToToken(h.usecase.AccessToken(user, authCtx), h.usecase.RefreshToken(user, authCtx)),
+		User:          auth.ToUser(user),
+		RecoveryCodes: codes,
+	}, "")
+}
+
+// @Router /api/v1/auth/reauth [post]
+// @Summary Re-authenticate to upgrade the session
+// @Description Issues a new token pair with a fresh auth_time. Password and TOTP code together give acr=mfa.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body auth.ReauthRequest true "Re-authentication"
+// @Success 200 {object} dto.BaseResponse{data=auth.AuthLoginResponse}
+func (h *AuthHandler) Reauth(c *gin.Context) {
+	var payload auth.ReauthRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	authCtx, err := h.usecase.Reauthenticate(c.Request.Context(), user, payload.Password, payload.Code)
+	if err != nil {
+		if h.accountError(c, err) {
+			return
+		}
+		dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+		return
+	}
+	authCtx.SessionID = sessionID(c)
+	dto.JSON(c, http.StatusOK, auth.AuthLoginResponse{
+		Token: auth.ToToken(h.usecase.AccessToken(user, authCtx), h.usecase.RefreshToken(user, authCtx)),
+		User:  auth.ToUser(user),
+	}, "")
+}
+
+// @Router /api/v1/auth/mfa/totp/enroll [post]
+// @Summary Start TOTP enrollment
+// @Tags mfa
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=auth.TotpEnrollResponse}
+func (h *AuthHandler) EnrollTotp(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	secret, uri, err := h.usecase.EnrollTotp(c.Request.Context(), user)
+	if err != nil {
+		h.mfaError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, auth.TotpEnrollResponse{Secret: secret, URI: uri}, "")
+}
+
+// @Router /api/v1/auth/mfa/totp/reenroll [post]
+// @Summary Replace TOTP secret
+// @Description Current code is required. The old secret stays active until the new one is confirmed.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body auth.TotpCodeRequest true "Current TOTP code"
+// @Success 200 {object} dto.BaseResponse{data=auth.TotpEnrollResponse}
+func (h *AuthHandler) ReenrollTotp(c *gin.Context) {
+	var payload auth.TotpCodeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	secret, uri, err := h.usecase.ReenrollTotp(c.Request.Context(), user, payload.Code)
+	if err != nil {
+		h.mfaError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, auth.TotpEnrollResponse{Secret: secret, URI: uri}, "")
+}
+
+// @Router /api/v1/auth/mfa/totp/confirm [post]
+// @Summary Confirm TOTP enrollment with the first code
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body auth.TotpCodeRequest true "TOTP code"
+// @Success 200 {object} dto.BaseResponse{data=auth.RecoveryCodesResponse}
+func (h *AuthHandler) ConfirmTotp(c *gin.Context) {
+	var payload auth.TotpCodeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	codes, err := h.usecase.ConfirmTotp(c.Request.Context(), user, payload.Code)
+	if err != nil {
+		h.mfaError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, auth.RecoveryCodesResponse{RecoveryCodes: codes}, "")
+}
+
+// @Router /api/v1/auth/mfa/recovery-codes [post]
+// @Summary Regenerate recovery codes
+// @Description Invalidates all previous codes. Requires the password (if set) and a current TOTP code.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body auth.RecoveryCodesRequest true "Re-authentication"
+// @Success 200 {object} dto.BaseResponse{data=auth.RecoveryCodesResponse}
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var payload auth.RecoveryCodesRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	codes, err := h.usecase.RegenerateRecoveryCodes(c.Request.Context(), user, payload.Password, payload.Code)
+	if err != nil {
+		h.mfaError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, auth.RecoveryCodesResponse{RecoveryCodes: codes}, "")
+}
+
+// @Router /api/v1/auth/mfa/totp/disable [post]
+// @Summary Disable TOTP
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body auth.TotpCodeRequest true "TOTP code"
+// @Success 200 {object} dto.BaseResponse
+func (h *AuthHandler) DisableTotp(c *gin.Context) {
+	var payload auth.TotpCodeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if err := h.usecase.DisableTotp(c.Request.Context(), user, payload.Code); err != nil {
+		h.mfaError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, nil, "")
+}
+
+func (h *AuthHandler) mfaError(c *gin.Context, err error) {
+	if h.accountError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, auth.ErrInvalidTotpCode),
+		errors.Is(err, auth.ErrInvalidRecoveryCode),
+		errors.Is(err, auth.ErrInvalidPassword):
+		dto.JSON(c, http.StatusForbidden, nil, err.Error())
+	case errors.Is(err, auth.ErrTotpAlreadyEnabled),
+		errors.Is(err, auth.ErrTotpNotEnabled),
+		errors.Is(err, auth.ErrTotpNotEnrolled):
+		dto.JSON(c, http.StatusBadRequest, nil, err.Error())
+	default:
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+	}
+}
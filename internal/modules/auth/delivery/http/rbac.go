@@ -0,0 +1,131 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/JscorpTech/auth/internal/dto"
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @Router /api/v1/auth/admin/permissions [get]
+// @Summary List permissions
+// @Tags admin
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]auth.PermissionDTO}
+func (h *AuthHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.usecase.ListPermissions(c.Request.Context())
+	if err != nil {
+		h.rbacError(c, err)
+		return
+	}
+	data := make([]auth.PermissionDTO, 0, len(permissions))
+	for i := range permissions {
+		data = append(data, auth.ToPermission(&permissions[i]))
+	}
+	dto.JSON(c, http.StatusOK, data, "")
+}
+
+// @Router /api/v1/auth/admin/permissions [post]
+// @Summary Create permission
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body auth.PermissionRequest true "Permission"
+// @Success 201 {object} dto.BaseResponse{data=auth.PermissionDTO}
+func (h *AuthHandler) CreatePermission(c *gin.Context) {
+	var payload auth.PermissionRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	permission, err := h.usecase.CreatePermission(c.Request.Context(), payload.Code, payload.Description)
+	if err != nil {
+		h.rbacError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusCreated, auth.ToPermission(permission), "")
+}
+
+// @Router /api/v1/auth/admin/permissions/{code} [delete]
+// @Summary Delete permission
+// @Description Also removes the permission from every role.
+// @Tags admin
+// @Produce json
+// @Param code path string true "Permission code"
+// @Success 200 {object} dto.BaseResponse
+func (h *AuthHandler) DeletePermission(c *gin.Context) {
+	if err := h.usecase.DeletePermission(c.Request.Context(), c.Param("code")); err != nil {
+		h.rbacError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, nil, "")
+}
+
+// @Router /api/v1/auth/admin/roles [get]
+// @Summary List roles with permissions
+// @Tags admin
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]auth.RoleDTO}
+func (h *AuthHandler) ListRoles(c *gin.Context) {
+	roles, err := h.usecase.ListRoles(c.Request.Context())
+	if err != nil {
+		h.rbacError(c, err)
+		return
+	}
+	data := make([]auth.RoleDTO, 0, len(roles))
+	for role, permissions := range roles {
+		data = append(data, auth.RoleDTO{Role: role, Permissions: permissions})
+	}
+	slices.SortFunc(data, func(a, b auth.RoleDTO) int {
+		switch {
+		case a.Role < b.Role:
+			return -1
+		case a.Role > b.Role:
+			return 1
+		}
+		return 0
+	})
+	dto.JSON(c, http.StatusOK, data, "")
+}
+
+// @Router /api/v1/auth/admin/roles/{role}/permissions [put]
+// @Summary Replace role permissions
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param role path string true "Role"
+// @Param request body auth.RolePermissionsRequest true "Permission codes"
+// @Success 200 {object} dto.BaseResponse{data=auth.RoleDTO}
+func (h *AuthHandler) SetRolePermissions(c *gin.Context) {
+	var payload auth.RolePermissionsRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	role := auth.Role(c.Param("role"))
+	permissions, err := h.usecase.SetRolePermissions(c.Request.Context(), role, payload.Permissions)
+	if err != nil {
+		h.rbacError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, auth.RoleDTO{Role: role, Permissions: permissions}, "")
+}
+
+func (h *AuthHandler) rbacError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auth.ErrPermissionNotFound):
+		dto.JSON(c, http.StatusNotFound, nil, err.Error())
+	case errors.Is(err, auth.ErrPermissionExists):
+		dto.JSON(c, http.StatusConflict, nil, err.Error())
+	case errors.Is(err, auth.ErrRoleImmutable):
+		dto.JSON(c, http.StatusBadRequest, nil, err.Error())
+	default:
+		h.logger.Error("rbac error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+	}
+}
@@ -1,23 +1,95 @@
 package http
 
 import (
+	"time"
+
 	"github.com/JscorpTech/auth/internal/config"
 	"github.com/JscorpTech/auth/internal/middlewares"
+	"github.com/JscorpTech/auth/internal/modules/auth"
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterAuthRoutes(cfg *config.Config, router *gin.RouterGroup, h *AuthHandler) {
+func RegisterAuthRoutes(cfg *config.Config, router *gin.RouterGroup, h *AuthHandler, limits *middlewares.RateLimiter, captcha *middlewares.Captcha) {
+	login := limits.Policy("login", middlewares.KeyAll(middlewares.ByIP, limits.ByPhone))
+	// bitta IP dan ko'p raqamga parol sinash va telefonsiz kirish usullari uchun
+	loginIP := limits.Policy("login_ip", middlewares.ByIP)
+	// kod tekshiruvi parol byudjetidan alohida: IP almashtirib bitta raqamning kodini
+	// terib bo'lmasin, bitta IP dan ko'p raqamning kodini ham
+	otpVerify := []gin.HandlerFunc{limits.Policy("otp_verify", limits.ByPhone), limits.Policy("otp_verify_ip", middlewares.ByIP)}
+	// OTP yuboradigan route lar: raqamga va IP ga alohida byudjet
+	otp := []gin.HandlerFunc{limits.Policy("otp", limits.ByPhone), limits.Policy("otp_ip", middlewares.ByIP)}
+	// 2FA ni o'zgartiradigan route lar: kodni terish foydalanuvchi bo'yicha cheklanadi
+	mfa := limits.Policy("mfa", middlewares.ByUser)
 	public := router.Group("")
 	{
-		public.POST("/login", h.Login)
-		public.POST("/register", h.Register)
-		public.POST("/refresh", h.RefreshToken)
-		public.POST("/confirm", h.Confirm)
-		public.POST("/google", h.Google)
+		public.POST("/login", loginIP, login, h.Login)
+		public.POST("/login/mfa", loginIP, limits.Policy("mfa", limits.ByMfaToken), h.LoginMfa)
+		public.POST("/login/mfa/enroll", loginIP, limits.Policy("mfa", limits.ByMfaToken), h.LoginMfaEnroll)
+		public.POST("/login/mfa/enroll/confirm", loginIP, limits.Policy("mfa", limits.ByMfaToken), h.LoginMfaEnrollConfirm)
+		public.POST("/register", append(otp, captcha.Require("register"), h.Register)...)
+		public.POST("/refresh", limits.Policy("refresh", middlewares.ByIP), h.RefreshToken)
+		public.POST("/confirm", append(otpVerify, h.Confirm)...)
+		public.POST("/social/:provider", loginIP, h.Social)
+		public.POST("/telegram", loginIP, h.Telegram)
+		public.POST("/magic-link", limits.Policy("otp_ip", middlewares.ByIP), h.MagicLink)
+		public.POST("/magic-link/verify", loginIP, h.VerifyMagicLink)
+		public.POST("/password/reset", append(otp, captcha.Require("password_reset"), h.RequestPasswordReset)...)
+		public.POST("/password/reset/confirm", append(otpVerify, h.ResetPassword)...)
+		public.POST("/devices/revoke", loginIP, h.RevokeDeviceByToken)
 	}
 	private := router.Group("")
 	private.Use(middlewares.AuthMiddleware(cfg, h.logger))
 	{
 		private.GET("/me", h.Me)
+		private.GET("/identities", h.Identities)
+		private.GET("/me/devices", h.Devices)
+	}
+	// impersonation tokeni bilan akkaunt va xavfsizlik sozlamalarini o'zgartirib bo'lmaydi
+	self := router.Group("")
+	self.Use(middlewares.AuthMiddleware(cfg, h.logger), middlewares.DenyImpersonation())
+	{
+		self.PUT("/me/otp-channel", h.SetOtpChannel)
+		self.POST("/password/change", limits.Policy("reauth", middlewares.ByUser), h.ChangePassword)
+		self.POST("/reauth", limits.Policy("reauth", middlewares.ByUser), h.Reauth)
+		self.POST("/mfa/totp/enroll", middlewares.RequireRecentAuth(5*time.Minute), h.EnrollTotp)
+		self.POST("/mfa/totp/reenroll", mfa, middlewares.RequireRecentAuth(5*time.Minute), h.ReenrollTotp)
+		self.POST("/mfa/totp/confirm", mfa, middlewares.RequireRecentAuth(5*time.Minute), h.ConfirmTotp)
+		self.POST("/mfa/totp/disable", mfa, middlewares.RequireRecentAuth(5*time.Minute), h.DisableTotp)
+		self.POST("/mfa/recovery-codes", mfa, h.RegenerateRecoveryCodes)
+		self.POST("/social/:provider/link", middlewares.RequireRecentAuth(5*time.Minute), h.LinkIdentity)
+		self.DELETE("/social/:provider/link", middlewares.RequireRecentAuth(5*time.Minute), h.UnlinkIdentity)
+		self.DELETE("/me/devices/:id", h.RevokeDevice)
+	}
+
+	admin := router.Group("/admin")
+	admin.Use(
+		middlewares.AdminIPFilter(cfg),
+		middlewares.AuthMiddleware(cfg, h.logger),
+		middlewares.ActiveUser(h.usecase),
+		middlewares.DenyImpersonation(),
+		middlewares.RequireRole(auth.RoleAdmin, auth.RoleSuper),
+		middlewares.RequireACR(auth.AcrMfa),
+	)
+	{
+		roles := admin.Group("", middlewares.RequirePermission(auth.PermRolesManage))
+		roles.GET("/permissions", h.ListPermissions)
+		roles.POST("/permissions", h.CreatePermission)
+		roles.DELETE("/permissions/:code", h.DeletePermission)
+		roles.GET("/roles", h.ListRoles)
+		roles.PUT("/roles/:role/permissions", h.SetRolePermissions)
+
+		read := middlewares.RequirePermission(auth.PermUsersRead)
+		write := middlewares.RequirePermission(auth.PermUsersWrite)
+		admin.GET("/users", read, h.ListUsers)
+		admin.GET("/users/:id", read, h.GetUser)
+		admin.PUT("/users/:id/role", write, h.ChangeRole)
+		admin.POST("/users/:id/activate", write, h.ActivateUser)
+		admin.POST("/users/:id/deactivate", write, h.DeactivateUser)
+		admin.POST("/users/:id/suspend", write, h.SuspendUser)
+		admin.POST("/users/:id/unsuspend", write, h.UnsuspendUser)
+		admin.POST("/users/:id/unlock", write, h.UnlockUser)
+		admin.POST("/users/:id/password-reset", write, h.ForcePasswordReset)
+		admin.POST("/users/:id/revoke-sessions", write, h.RevokeSessions)
+		admin.POST("/users/:id/impersonate", middlewares.RequirePermission(auth.PermImpersonate), h.Impersonate)
 	}
 }
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JscorpTech/auth/internal/dto"
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/JscorpTech/auth/internal/social"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @Router /api/v1/auth/telegram [post]
+// @Summary Telegram Login Widget authentication
+// @Description Payload is the unchanged Login Widget callback data signed with the bot token.
+// @Tags social
+// @Accept json
+// @Produce json
+// @Param request body auth.TelegramAuthRequest true "Login Widget data"
+// @Success 200 {object} dto.BaseResponse{data=auth.AuthLoginResponse}
+// @Success 202 {object} dto.BaseResponse{data=auth.AuthMfaChallengeResponse}
+func (h *AuthHandler) Telegram(c *gin.Context) {
+	var payload auth.TelegramAuthRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	user, err := h.usecase.TelegramAuth(c.Request.Context(), &payload)
+	if err != nil {
+		if h.accountError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, auth.ErrTelegramDisabled):
+			dto.JSON(c, http.StatusNotFound, nil, err.Error())
+		case errors.Is(err, auth.ErrInvalidTelegramAuth),
+			errors.Is(err, auth.ErrTelegramAuthExpired):
+			dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+		default:
+			h.logger.Error("telegram auth error", zap.Error(err))
+			dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		}
+		return
+	}
+	h.loginResponse(c, user, auth.NewAuthContext(auth.AcrBasic, auth.AmrFederated))
+}
+
+// @Router /api/v1/auth/identities [get]
+// @Summary Linked external identities
+// @Tags social
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]auth.ExternalIdentityDTO}
+func (h *AuthHandler) Identities(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	identities, err := h.usecase.GetIdentities(c.Request.Context(), user)
+	if err != nil {
+		h.socialError(c, err)
+		return
+	}
+	data := make([]auth.ExternalIdentityDTO, 0, len(identities))
+	for i := range identities {
+		data = append(data, auth.ToExternalIdentity(&identities[i]))
+	}
+	dto.JSON(c, http.StatusOK, data, "")
+}
+
+// @Router /api/v1/auth/social/{provider}/link [post]
+// @Summary Link external identity to the current account
+// @Tags social
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name (google, apple, github, ...)"
+// @Param request body auth.SocialAuthRequest true "Provider token"
+// @Success 200 {object} dto.BaseResponse{data=auth.ExternalIdentityDTO}
+func (h *AuthHandler) LinkIdentity(c *gin.Context) {
+	var payload auth.SocialAuthRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	identity, err := h.usecase.LinkIdentity(c.Request.Context(), user, c.Param("provider"), payload.Token)
+	if err != nil {
+		h.socialError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, auth.ToExternalIdentity(identity), "")
+}
+
+// @Router /api/v1/auth/social/{provider}/link [delete]
+// @Summary Unlink external identity
+// @Tags social
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} dto.BaseResponse
+func (h *AuthHandler) UnlinkIdentity(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if err := h.usecase.UnlinkIdentity(c.Request.Context(), user, c.Param("provider")); err != nil {
+		h.socialError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, nil, "")
+}
+
+func (h *AuthHandler) socialError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, social.ErrUnknownProvider),
+		errors.Is(err, auth.ErrIdentityNotLinked):
+		dto.JSON(c, http.StatusNotFound, nil, err.Error())
+	case errors.Is(err, social.ErrInvalidToken):
+		dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+	case errors.Is(err, auth.ErrIdentityAlreadyLinked),
+		errors.Is(err, auth.ErrLastLoginMethod):
+		dto.JSON(c, http.StatusConflict, nil, err.Error())
+	default:
+		h.logger.Error("social identity error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+	}
+}
@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/modules/audit"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const deviceTokenChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// userAgentBrowsers tartib muhim: Edge va Opera UA sida Chrome, Chrome nikida Safari ham bor.
+var userAgentBrowsers = []struct{ token, name string }{
+	{"Edg", "Edge"},
+	{"OPR", "Opera"},
+	{"YaBrowser", "Yandex"},
+	{"Firefox", "Firefox"},
+	{"FxiOS", "Firefox"},
+	{"CriOS", "Chrome"},
+	{"Chrome", "Chrome"},
+	{"Safari", "Safari"},
+	{"okhttp", "Android app"},
+	{"Dart", "Flutter app"},
+	{"curl", "curl"},
+}
+
+var userAgentSystems = []struct{ token, name string }{
+	{"Windows", "Windows"},
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iOS"},
+	{"Mac OS X", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// UserAgentFamily UA ni "Chrome on Windows" ko'rinishiga keltiradi. Versiyalar hisobga
+// olinmaydi, aks holda har bir brauzer yangilanishi yangi qurilma bo'lib ko'rinadi.
+func UserAgentFamily(userAgent string) string {
+	browser, system := "Other", ""
+	for _, b := range userAgentBrowsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, s := range userAgentSystems {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+	if system == "" {
+		return browser
+	}
+	return browser + " on " + system
+}
+
+// IPNetwork IPv4 uchun /24, IPv6 uchun /48 tarmoq. Mobil operatorlarda manzil tez-tez
+// almashadi, shuning uchun aniq IP solishtirilmaydi.
+func IPNetwork(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// trackDevice kirish kontekstini foydalanuvchining ma'lum qurilmalari bilan solishtiradi va
+// authCtx.SessionID ni o'rnatadi. Yangi qurilmada, agar bu birinchi kirish bo'lmasa,
+// egasiga bekor qilish havolasi bilan xabar yuboriladi. Xatolar kirishni to'xtatmaydi.
+func (a *AuthUsecaseImpl) trackDevice(ctx context.Context, user *User, authCtx *AuthContext) {
+	client := audit.ClientFromContext(ctx)
+	ip := net.ParseIP(client.IP)
+	device := &Device{
+		UserID:     user.ID,
+		Family:     UserAgentFamily(client.UserAgent),
+		Network:    IPNetwork(ip),
+		UserAgent:  client.UserAgent,
+		LastIP:     client.IP,
+		LastSeenAt: time.Now(),
+	}
+	if a.geo != nil && ip != nil {
+		device.Country = a.geo.Country(ip)
+	}
+	device.Fingerprint = utils.HashToken(device.Family + "|" + device.Network + "|" + device.Country)
+
+	known, err := a.repo.GetDevice(ctx, user.ID, device.Fingerprint)
+	if err == nil {
+		a.repo.UpdateDevice(ctx, known, map[string]any{
+			"last_ip":      device.LastIP,
+			"user_agent":   device.UserAgent,
+			"last_seen_at": device.LastSeenAt,
+		})
+		authCtx.SessionID = known.ID
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		a.logger.Error("device lookup error", zap.Uint("user_id", user.ID), zap.Error(err))
+		return
+	}
+
+	first := a.repo.CountDevices(ctx, user.ID) == 0
+	token, err := utils.SecureRandomString(32, deviceTokenChars)
+	if err != nil {
+		a.logger.Error("device token error", zap.Error(err))
+		return
+	}
+	device.RevokeToken = utils.HashToken(token)
+	if _, err := a.repo.CreateDevice(ctx, device); err != nil {
+		a.logger.Error("device create error", zap.Uint("user_id", user.ID), zap.Error(err))
+		return
+	}
+	authCtx.SessionID = device.ID
+	a.record(ctx, audit.TypeDeviceNew, user, map[string]any{
+		"device_id": device.ID,
+		"family":    device.Family,
+		"network":   device.Network,
+		"country":   device.Country,
+	})
+	if !first && a.cfg.DeviceAlerts {
+		a.notifyNewDevice(user, device, token)
+	}
+}
+
+// notifyNewDevice xabarni foydalanuvchi tanlagan kanal bo'yicha bitta joyga yuboradi:
+// Telegram, bo'lmasa email, bo'lmasa SMS.
+func (a *AuthUsecaseImpl) notifyNewDevice(user *User, device *Device, token string) {
+	place := device.Network
+	if device.Country != "" {
+		place = device.Country + ", " + place
+	}
+	msg := "Hisobingizga yangi qurilmadan kirildi: " + device.Family + " (" + place + "). " +
+		"Bu siz bo'lmasangiz, sessiyani bekor qiling va parolni almashtiring: " +
+		a.cfg.DeviceRevokeURL + "?token=" + url.QueryEscape(token)
+	var err error
+	switch {
+	case a.telegram != nil && user.Phone != nil && user.OtpChannel == OtpChannelTelegram:
+		err = a.telegram.Send(*user.Phone, msg)
+	case user.Email != nil && *user.Email != "":
+		err = a.email.Send(*user.Email, msg)
+	case user.Phone != nil:
+		err = a.sms.Send(*user.Phone, msg)
+	default:
+		return
+	}
+	if err != nil {
+		a.logger.Error("new device alert error", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+}
+
+func (a *AuthUsecaseImpl) ListDevices(ctx context.Context, user *User) ([]Device, error) {
+	return a.repo.GetDevices(ctx, user.ID)
+}
+
+// RevokeDevice foydalanuvchining o'z qurilmasini bekor qiladi, shu qurilmaga berilgan
+// access va refresh tokenlar CurrentUser da rad etiladi.
+func (a *AuthUsecaseImpl) RevokeDevice(ctx context.Context, user *User, id uint) error {
+	device, err := a.repo.GetDeviceByID(ctx, id)
+	if err != nil || device.UserID != user.ID {
+		return ErrDeviceNotFound
+	}
+	return a.revokeDevice(ctx, user, device, "self")
+}
+
+// RevokeDeviceByToken ogohlantirish xabaridagi havola orqali, login talab qilinmaydi.
+func (a *AuthUsecaseImpl) RevokeDeviceByToken(ctx context.Context, token string) error {
+	device, err := a.repo.GetDeviceByRevokeToken(ctx, utils.HashToken(token))
+	if err != nil {
+		return ErrInvalidDeviceToken
+	}
+	user, err := a.repo.GetID(ctx, int64(device.UserID))
+	if err != nil {
+		return ErrInvalidDeviceToken
+	}
+	return a.revokeDevice(ctx, user, device, "link")
+}
+
+func (a *AuthUsecaseImpl) revokeDevice(ctx context.Context, user *User, device *Device, method string) error {
+	if device.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	if err := a.repo.UpdateDevice(ctx, device, map[string]any{"revoked_at": now}); err != nil {
+		return err
+	}
+	device.RevokedAt = &now
+	a.record(ctx, audit.TypeDeviceRevoked, user, map[string]any{
+		"device_id": device.ID,
+		"method":    method,
+	})
+	return nil
+}
+
+// deviceRevoked sid claim i bo'lmagan (eski yoki impersonation) tokenlar uchun false.
+func (a *AuthUsecaseImpl) deviceRevoked(ctx context.Context, userID uint, sessionID uint) bool {
+	if sessionID == 0 {
+		return false
+	}
+	device, err := a.repo.GetDeviceByID(ctx, sessionID)
+	return err != nil || device.UserID != userID || device.RevokedAt != nil
+}
@@ -1,20 +1,28 @@
 package auth
 
+import (
+	"errors"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/dto"
+)
+
 type AuthLoginRequest struct {
 	Phone    string `json:"phone" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
 
 type UserDTO struct {
-	ID              uint    `json:"id"`
-	FirstName       string  `json:"first_name"`
-	LastName        string  `json:"last_name"`
-	Phone           *string `json:"phone"`
-	Email           *string `json:"email"`
-	UserName        *string `json:"username"`
-	Balance         int     `json:"balance"`
-	TemplateBalance int     `json:"template_balance"`
-	Role            Role    `json:"role"`
+	ID              uint       `json:"id"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	Phone           *string    `json:"phone"`
+	Email           *string    `json:"email"`
+	UserName        *string    `json:"username"`
+	Balance         int        `json:"balance"`
+	TemplateBalance int        `json:"template_balance"`
+	Role            Role       `json:"role"`
+	OtpChannel      OtpChannel `json:"otp_channel"`
 }
 
 type AuthLoginResponse struct {
@@ -22,8 +30,69 @@ type AuthLoginResponse struct {
 	Token TokenDTO `json:"token"`
 }
 
+type AuthMfaChallengeResponse struct {
+	MfaRequired bool     `json:"mfa_required"`
+	MfaToken    string   `json:"mfa_token"`
+	Methods     []string `json:"methods"`
+	// SetupRequired admin va staff 2FA siz kira olmaydi: mfa_token bilan
+	// /login/mfa/enroll orqali TOTP ulanadi
+	SetupRequired bool `json:"setup_required,omitempty"`
+}
+
+type AuthMfaEnrollRequest struct {
+	MfaToken string `json:"mfa_token" binding:"required"`
+}
+
+type AuthMfaEnrollConfirmRequest struct {
+	MfaToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type AuthMfaEnrollConfirmResponse struct {
+	User          UserDTO  `json:"user"`
+	Token         TokenDTO `json:"token"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type AuthLoginMfaRequest struct {
+	MfaToken string `json:"mfa_token" binding:"required"`
+	// Code 6 xonali TOTP kod yoki recovery kod
+	Code string `json:"code" binding:"required"`
+}
+
+type TotpCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type ReauthRequest struct {
+	Password string `json:"password"`
+	// Code TOTP yoki recovery kod
+	Code string `json:"code"`
+}
+
+type RecoveryCodesRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TotpEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
 type AuthMeResponse struct {
-	User UserDTO `json:"user"`
+	User UserDTO      `json:"user"`
+	Mfa  MfaStatusDTO `json:"mfa"`
+}
+
+type MfaStatusDTO struct {
+	Enabled                bool     `json:"enabled"`
+	Methods                []string `json:"methods"`
+	RecoveryCodesRemaining int64    `json:"recovery_codes_remaining"`
 }
 
 type AuthRefreshTokenRequest struct {
@@ -39,7 +108,8 @@ type AuthRegisterRequest struct {
 	LastName  string  `json:"last_name"`
 	Email     *string `json:"email"`
 	Phone     *string `json:"phone" binding:"required"`
-	Password  string  `json:"password" binding:"required,min=8"`
+	// Password parol siyosati usecase da tekshiriladi
+	Password string `json:"password" binding:"required"`
 }
 
 type TokenDTO struct {
@@ -57,8 +127,207 @@ type AuthConfirmRequest struct {
 	Otp   string `json:"otp" binding:"required"`
 }
 
-type GoogleAuthRequest struct {
-	IDToken string `json:"id_token" binding:"required"`
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	// DeviceID berilsa link faqat shu qurilmada tasdiqlanadi
+	DeviceID string `json:"device_id"`
+}
+
+type MagicLinkVerifyRequest struct {
+	Token    string `json:"token" binding:"required"`
+	DeviceID string `json:"device_id"`
+}
+
+type SocialAuthRequest struct {
+	// Token OIDC providerlar uchun ID token, GitHub uchun OAuth access token
+	Token string `json:"token" binding:"required"`
+}
+
+// TelegramAuthRequest Login Widget callback ma'lumotlari, maydonlar o'zgarishsiz yuboriladi.
+type TelegramAuthRequest struct {
+	ID        int64  `json:"id" binding:"required"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Username  string `json:"username"`
+	PhotoURL  string `json:"photo_url"`
+	AuthDate  int64  `json:"auth_date" binding:"required"`
+	Hash      string `json:"hash" binding:"required"`
+}
+
+type OtpChannelRequest struct {
+	Channel OtpChannel `json:"channel" binding:"required,oneof=sms telegram"`
+}
+
+type AdminUserDTO struct {
+	UserDTO
+	IsActive              bool       `json:"is_active"`
+	IsStaff               bool       `json:"is_staff"`
+	Confirmed             bool       `json:"confirmed"`
+	DateJoined            *time.Time `json:"date_joined"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	SessionsRevokedAt     *time.Time `json:"sessions_revoked_at"`
+	SuspendedUntil        *time.Time `json:"suspended_until"`
+	SuspensionReason      string     `json:"suspension_reason,omitempty"`
+	FailedLoginAttempts   int        `json:"failed_login_attempts"`
+	LockedUntil           *time.Time `json:"locked_until"`
+}
+
+type AdminUserListRequest struct {
+	dto.PageRequest
+	// Search telefon, email yoki ism bo'yicha qidiradi
+	Search    string `form:"search"`
+	Role      *Role  `form:"role"`
+	IsActive  *bool  `form:"is_active"`
+	Confirmed *bool  `form:"confirmed"`
+}
+
+const (
+	AccountStatusDisabled  = "account_disabled"
+	AccountStatusSuspended = "account_suspended"
+	AccountStatusLocked    = "account_locked"
+)
+
+// AccountStatusDTO bloklangan akkaunt uchun 403 javobidagi data, Code bo'yicha mijoz
+// oddiy avtorizatsiya xatosidan ajrata oladi.
+type AccountStatusDTO struct {
+	Code   string     `json:"code"`
+	Reason string     `json:"reason,omitempty"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+func AccountStatus(err error) (*AccountStatusDTO, bool) {
+	var suspended *SuspendedError
+	var locked *LockedError
+	switch {
+	case errors.As(err, &suspended):
+		return &AccountStatusDTO{Code: AccountStatusSuspended, Reason: suspended.Reason, Until: &suspended.Until}, true
+	case errors.As(err, &locked):
+		return &AccountStatusDTO{Code: AccountStatusLocked, Until: &locked.Until}, true
+	case errors.Is(err, ErrAccountDisabled):
+		return &AccountStatusDTO{Code: AccountStatusDisabled}, true
+	}
+	return nil, false
+}
+
+type SuspendRequest struct {
+	Reason string    `json:"reason" binding:"required,max=255"`
+	Until  time.Time `json:"until" binding:"required"`
+}
+
+type ImpersonationResponse struct {
+	Access    string    `json:"access"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      UserDTO   `json:"user"`
+}
+
+type AdminRoleRequest struct {
+	Role Role `json:"role" binding:"required"`
+}
+
+type PasswordResetRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+type PasswordResetConfirmRequest struct {
+	Phone    string `json:"phone" binding:"required"`
+	Otp      string `json:"otp" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	Password        string `json:"password" binding:"required"`
+}
+
+func ToAdminUser(user *User) AdminUserDTO {
+	return AdminUserDTO{
+		UserDTO:               ToUser(user),
+		IsActive:              user.IsActive != nil && *user.IsActive,
+		IsStaff:               user.IsStaff != nil && *user.IsStaff,
+		Confirmed:             user.ValidatedAT != nil,
+		DateJoined:            user.DateJoined,
+		PasswordResetRequired: user.PasswordResetRequired,
+		SessionsRevokedAt:     user.SessionsRevokedAt,
+		SuspendedUntil:        user.SuspendedUntil,
+		SuspensionReason:      user.SuspensionReason,
+		FailedLoginAttempts:   user.FailedLoginAttempts,
+		LockedUntil:           user.LockedUntil,
+	}
+}
+
+type PermissionDTO struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+type PermissionRequest struct {
+	Code        string `json:"code" binding:"required,max=64"`
+	Description string `json:"description"`
+}
+
+type RoleDTO struct {
+	Role        Role     `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+type RolePermissionsRequest struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+func ToPermission(permission *Permission) PermissionDTO {
+	return PermissionDTO{
+		Code:        permission.Code,
+		Description: permission.Description,
+	}
+}
+
+type ExternalIdentityDTO struct {
+	Provider      string     `json:"provider"`
+	Email         *string    `json:"email"`
+	EmailVerified bool       `json:"email_verified"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastLoginAt   *time.Time `json:"last_login_at"`
+}
+
+func ToExternalIdentity(identity *ExternalIdentity) ExternalIdentityDTO {
+	return ExternalIdentityDTO{
+		Provider:      identity.Provider,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+		CreatedAt:     identity.CreatedAt,
+		LastLoginAt:   identity.LastLoginAt,
+	}
+}
+
+type RevokeDeviceRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type DeviceDTO struct {
+	ID         uint       `json:"id"`
+	Family     string     `json:"family"`
+	Network    string     `json:"network"`
+	Country    string     `json:"country"`
+	LastIP     string     `json:"last_ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	// Current so'rov shu qurilmadagi token bilan yuborilgan
+	Current bool `json:"current"`
+}
+
+func ToDevice(device *Device, sessionID uint) DeviceDTO {
+	return DeviceDTO{
+		ID:         device.ID,
+		Family:     device.Family,
+		Network:    device.Network,
+		Country:    device.Country,
+		LastIP:     device.LastIP,
+		CreatedAt:  device.CreatedAt,
+		LastSeenAt: device.LastSeenAt,
+		RevokedAt:  device.RevokedAt,
+		Current:    device.ID == sessionID,
+	}
 }
 
 func ToToken(access string, refresh string) TokenDTO {
@@ -70,12 +339,13 @@ func ToToken(access string, refresh string) TokenDTO {
 
 func ToUser(user *User) UserDTO {
 	return UserDTO{
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Phone:     user.Phone,
-		Email:     user.Email,
-		Role:      user.Role,
-		ID:        user.ID,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Phone:      user.Phone,
+		Email:      user.Email,
+		Role:       user.Role,
+		ID:         user.ID,
+		OtpChannel: user.OtpChannel,
 	}
 }
 
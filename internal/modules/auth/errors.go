@@ -1,6 +1,9 @@
 package auth
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrUserAlreadyExists       = errors.New("user already exists")
@@ -9,4 +12,63 @@ var (
 	ErrInvalidPassword         = errors.New("Invalid password")
 	ErrInvalidCredentions      = errors.New("Invalid credentions")
 	ErrRateLimit               = errors.New("It takes 2 minutes to resend the SMS.")
+	ErrInvalidMfaToken         = errors.New("invalid mfa token")
+	ErrInvalidTotpCode         = errors.New("invalid totp code")
+	ErrTotpAlreadyEnabled      = errors.New("totp already enabled")
+	ErrTotpNotEnabled          = errors.New("totp not enabled")
+	ErrTotpNotEnrolled         = errors.New("totp enrollment not started")
+	ErrInvalidRecoveryCode     = errors.New("invalid recovery code")
+	ErrInvalidMagicLink        = errors.New("invalid or expired magic link")
+	ErrEmailNotProvided        = errors.New("email not provided by identity provider")
+	ErrEmailNotVerified        = errors.New("email not verified by identity provider")
+	ErrIdentityAlreadyLinked   = errors.New("identity already linked to another account")
+	ErrIdentityNotLinked       = errors.New("identity not linked")
+	ErrLastLoginMethod         = errors.New("can not unlink the only login method")
+	ErrTelegramDisabled        = errors.New("telegram login is not configured")
+	ErrInvalidTelegramAuth     = errors.New("invalid telegram auth data")
+	ErrTelegramAuthExpired     = errors.New("telegram auth data expired")
+	ErrOtpChannelUnavailable   = errors.New("otp channel is not available")
+	ErrPermissionExists        = errors.New("permission already exists")
+	ErrPermissionNotFound      = errors.New("permission not found")
+	ErrRoleImmutable           = errors.New("role permissions can not be changed")
+	ErrSessionRevoked          = errors.New("session revoked")
+	ErrUserNotFound            = errors.New("user not found")
+	ErrInvalidOtp              = errors.New("invalid otp")
+	ErrUnknownRole             = errors.New("unknown role")
+	ErrCannotManageUser        = errors.New("not allowed to manage this user")
+	ErrPasswordResetRequired   = errors.New("password reset required")
+	ErrAccountDisabled         = errors.New("account disabled")
+	ErrAccountSuspended        = errors.New("account suspended")
+	ErrInvalidSuspension       = errors.New("suspension end must be in the future")
+	ErrImpersonationForbidden  = errors.New("impersonated session can not do this")
+	ErrAccountLocked           = errors.New("account temporarily locked")
+	ErrDeviceNotFound          = errors.New("device not found")
+	ErrInvalidDeviceToken      = errors.New("invalid device revoke token")
 )
+
+// SuspendedError muddatli bloklash sababi va tugash vaqtini mijozga yetkazadi.
+type SuspendedError struct {
+	Reason string
+	Until  time.Time
+}
+
+func (e *SuspendedError) Error() string {
+	return ErrAccountSuspended.Error()
+}
+
+func (e *SuspendedError) Unwrap() error {
+	return ErrAccountSuspended
+}
+
+// LockedError ko'p marta noto'g'ri parol kiritilganda, bloklash qachon tugashini bildiradi.
+type LockedError struct {
+	Until time.Time
+}
+
+func (e *LockedError) Error() string {
+	return ErrAccountLocked.Error()
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrAccountLocked
+}
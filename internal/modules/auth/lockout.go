@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/modules/audit"
+	"go.uber.org/zap"
+)
+
+// failedLogin noto'g'ri parolni sanaydi va chegaraga yetganda akkauntni bloklaydi.
+// Shu urinish bloklashga olib kelgan bo'lsa true qaytaradi.
+func (a *AuthUsecaseImpl) failedLogin(ctx context.Context, user *User) bool {
+	if a.cfg.LockoutThreshold <= 0 {
+		return false
+	}
+	attempts, err := a.repo.IncrementFailedLogins(ctx, user)
+	if err != nil {
+		a.logger.Error("failed login counter error", zap.Uint("user_id", user.ID), zap.Error(err))
+		return false
+	}
+	user.FailedLoginAttempts = attempts
+	if int64(attempts) < a.cfg.LockoutThreshold {
+		return false
+	}
+	until := time.Now().Add(a.lockoutDuration(user.LockoutCount))
+	a.repo.Update(ctx, user, map[string]any{
+		"failed_login_attempts": 0,
+		"lockout_count":         user.LockoutCount + 1,
+		"locked_until":          until,
+	})
+	user.FailedLoginAttempts = 0
+	user.LockoutCount++
+	user.LockedUntil = &until
+	a.record(ctx, audit.TypeAccountLocked, user, map[string]any{"attempts": attempts, "until": until})
+	a.notifyLockout(user, until)
+	return true
+}
+
+// lockoutDuration har bir keyingi bloklashda muddat ikki barobar oshadi, LockoutMaxDuration dan oshmaydi.
+func (a *AuthUsecaseImpl) lockoutDuration(lockouts int) time.Duration {
+	duration := time.Duration(a.cfg.LockoutDuration) * time.Minute
+	limit := time.Duration(a.cfg.LockoutMaxDuration) * time.Minute
+	for i := 0; i < lockouts && duration < limit; i++ {
+		duration *= 2
+	}
+	return min(duration, limit)
+}
+
+func (a *AuthUsecaseImpl) resetFailedLogins(ctx context.Context, user *User) {
+	if user.FailedLoginAttempts == 0 && user.LockoutCount == 0 && user.LockedUntil == nil {
+		return
+	}
+	a.repo.Update(ctx, user, map[string]any{
+		"failed_login_attempts": 0,
+		"lockout_count":         0,
+		"locked_until":          nil,
+	})
+	user.FailedLoginAttempts = 0
+	user.LockoutCount = 0
+	user.LockedUntil = nil
+}
+
+// notifyLockout egasini telefon va email orqali ogohlantiradi, yuborishdagi xato kirishga ta'sir qilmaydi.
+func (a *AuthUsecaseImpl) notifyLockout(user *User, until time.Time) {
+	msg := "Hisobingizga ko'p marta noto'g'ri parol bilan kirishga urinildi. Kirish " + until.Format("2006-01-02 15:04") + " gacha bloklandi."
+	if user.Phone != nil {
+		if err := a.sms.Send(*user.Phone, msg); err != nil {
+			a.logger.Error("lockout sms error", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+	}
+	if user.Email != nil && *user.Email != "" {
+		if err := a.email.Send(*user.Email, msg); err != nil {
+			a.logger.Error("lockout email error", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+	}
+}
+
+func (a *AuthUsecaseImpl) Unlock(ctx context.Context, actor *User, target *User) error {
+	if !canManage(actor, target) {
+		return ErrCannotManageUser
+	}
+	a.resetFailedLogins(ctx, target)
+	a.recordAdmin(ctx, audit.TypeUserUnlocked, actor, target, nil)
+	return nil
+}
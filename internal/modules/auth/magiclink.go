@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	magicLinkResend = time.Minute
+	magicLinkChars  = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// SendMagicLink emailga bir martalik kirish havolasini yuboradi. Email topilmasa yoki
+// oxirgi havola yaqinda yuborilgan bo'lsa ham xatolik qaytarilmaydi, shunda endpoint
+// orqali ro'yxatdan o'tgan emaillarni aniqlab bo'lmaydi.
+func (a *AuthUsecaseImpl) SendMagicLink(ctx context.Context, email string, deviceID string) error {
+	user, err := a.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			a.logger.Info("magic link requested for unknown email")
+			return nil
+		}
+		return err
+	}
+	if last, err := a.repo.GetLastMagicLink(ctx, user.ID); err == nil && time.Since(last.CreatedAt) < magicLinkResend {
+		a.logger.Info("magic link resend throttled", zap.Uint("user_id", user.ID))
+		return nil
+	}
+	a.repo.DeleteExpiredMagicLinks(ctx)
+
+	jti, err := utils.SecureRandomString(32, magicLinkChars)
+	if err != nil {
+		return err
+	}
+	exp := time.Now().Add(time.Minute * time.Duration(a.cfg.MagicLinkExp))
+	claims := jwt.MapClaims{
+		"user_id":    user.ID,
+		"exp":        exp.Unix(),
+		"token_type": "magic_link",
+		"jti":        jti,
+	}
+	if deviceID != "" {
+		claims["dev"] = utils.HashToken(deviceID)
+	}
+	token, err := utils.CreateJWT(claims, a.cfg.PrivateKey)
+	if err != nil {
+		return err
+	}
+	if _, err := a.repo.CreateMagicLink(ctx, &MagicLink{UserID: user.ID, Jti: jti, Exp: exp}); err != nil {
+		return err
+	}
+	link := a.cfg.MagicLinkURL + "?token=" + url.QueryEscape(token)
+	return a.email.Send(email, "Tizimga kirish uchun havola: "+link)
+}
+
+func (a *AuthUsecaseImpl) VerifyMagicLink(ctx context.Context, token string, deviceID string) (*User, error) {
+	claims, err := utils.VerifyJWT(token, a.cfg.PublicKey)
+	if err != nil {
+		return nil, ErrInvalidMagicLink
+	}
+	if claims["token_type"] != "magic_link" {
+		return nil, ErrInvalidMagicLink
+	}
+	if dev, ok := claims["dev"].(string); ok {
+		if subtle.ConstantTimeCompare([]byte(dev), []byte(utils.HashToken(deviceID))) != 1 {
+			return nil, ErrInvalidMagicLink
+		}
+	}
+	jti, _ := claims["jti"].(string)
+	if a.cfg.MagicLinkSingleUse && !a.repo.UseMagicLink(ctx, jti) {
+		return nil, ErrInvalidMagicLink
+	}
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, ErrInvalidMagicLink
+	}
+	user, err := a.repo.GetID(ctx, int64(userID))
+	if err != nil {
+		return nil, ErrInvalidMagicLink
+	}
+	if err := a.CheckStatus(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
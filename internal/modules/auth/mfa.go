@@ -0,0 +1,323 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	MfaMethodTotp         = "totp"
+	MfaMethodRecoveryCode = "recovery_code"
+	// totpSkew soat farqi uchun oldingi va keyingi 30 soniyalik oynani ham qabul qiladi.
+	totpSkew = 1
+
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	recoveryCodeChars  = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+func (a *AuthUsecaseImpl) MfaEnabled(ctx context.Context, user *User) bool {
+	totp, err := a.repo.GetTotp(ctx, user.ID)
+	if err != nil {
+		return false
+	}
+	return totp.IsEnabled()
+}
+
+func (a *AuthUsecaseImpl) MfaMethods(ctx context.Context, user *User) []string {
+	methods := []string{}
+	if a.MfaEnabled(ctx, user) {
+		methods = append(methods, MfaMethodTotp)
+		if a.RecoveryCodesRemaining(ctx, user) > 0 {
+			methods = append(methods, MfaMethodRecoveryCode)
+		}
+	}
+	return methods
+}
+
+// MfaToken birinchi faktor natijasini (amr) saqlaydi, /login/mfa da ikkinchi faktor
+// unga qo'shiladi.
+func (a *AuthUsecaseImpl) MfaToken(user *User, authCtx *AuthContext) string {
+	claims := jwt.MapClaims{
+		"user_id":    user.ID,
+		"exp":        time.Now().Add(time.Minute * time.Duration(a.cfg.MfaExp)).Unix(),
+		"token_type": "mfa",
+		"jti":        utils.RandomString(20, "1234567890"),
+		"iat":        time.Now().Unix(),
+	}
+	authCtx.apply(claims)
+	token, err := utils.CreateJWT(claims, a.cfg.PrivateKey)
+	if err != nil {
+		a.logger.Error("create mfa token error", zap.Error(err))
+		return ""
+	}
+	return token
+}
+
+// CompleteLogin birinchi faktordan keyin token juftligini yoki, kirish ikkinchi faktorni
+// o'z ichiga olmagan (acr basic) bo'lsa, /login/mfa uchun challenge qaytaradi. 2FA siz
+// admin va staff ga token berilmaydi, challenge ularni TOTP ulashga yo'naltiradi.
+func (a *AuthUsecaseImpl) CompleteLogin(ctx context.Context, user *User, authCtx *AuthContext) (*AuthLoginResponse, *AuthMfaChallengeResponse) {
+	mfa := a.MfaEnabled(ctx, user)
+	if !AcrSatisfies(authCtx.Acr, AcrMfa) && (mfa || user.IsPrivileged()) {
+		return nil, &AuthMfaChallengeResponse{
+			MfaRequired:   true,
+			MfaToken:      a.MfaToken(user, authCtx),
+			Methods:       a.MfaMethods(ctx, user),
+			SetupRequired: !mfa,
+		}
+	}
+	a.LoginSucceeded(ctx, user, authCtx)
+	return &AuthLoginResponse{
+		Token: ToToken(a.AccessToken(user, authCtx), a.RefreshToken(user, authCtx)),
+		User:  ToUser(user),
+	}, nil
+}
+
+func (a *AuthUsecaseImpl) ValidateMfaToken(ctx context.Context, token string) (*User, *AuthContext, error) {
+	claims, err := utils.VerifyJWT(token, a.cfg.PublicKey)
+	if err != nil {
+		return nil, nil, ErrInvalidMfaToken
+	}
+	if claims["token_type"] != "mfa" {
+		return nil, nil, ErrInvalidMfaToken
+	}
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, nil, ErrInvalidMfaToken
+	}
+	user, err := a.repo.GetID(ctx, int64(userID))
+	if err != nil {
+		return nil, nil, ErrInvalidMfaToken
+	}
+	if user.IsLocked() {
+		return nil, nil, &LockedError{Until: *user.LockedUntil}
+	}
+	if err := a.CheckStatus(user); err != nil {
+		return nil, nil, err
+	}
+	return user, AuthContextFromClaims(claims), nil
+}
+
+func (a *AuthUsecaseImpl) EnrollTotp(ctx context.Context, user *User) (string, string, error) {
+	totp, err := a.repo.GetTotp(ctx, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", "", err
+	}
+	if totp != nil && totp.IsEnabled() {
+		return "", "", ErrTotpAlreadyEnabled
+	}
+	return a.startTotpEnrollment(ctx, user, totp)
+}
+
+func (a *AuthUsecaseImpl) ReenrollTotp(ctx context.Context, user *User, code string) (string, string, error) {
+	if err := a.verifyTotpCounted(ctx, user, code); err != nil {
+		return "", "", err
+	}
+	totp, err := a.repo.GetTotp(ctx, user.ID)
+	if err != nil {
+		return "", "", err
+	}
+	return a.startTotpEnrollment(ctx, user, totp)
+}
+
+// startTotpEnrollment yangi secretni pending holatda saqlaydi, u birinchi kod bilan
+// tasdiqlanmaguncha amaldagi secret ishlashda davom etadi.
+func (a *AuthUsecaseImpl) startTotpEnrollment(ctx context.Context, user *User, totp *Totp) (string, string, error) {
+	secret, err := utils.GenerateTotpSecret()
+	if err != nil {
+		return "", "", err
+	}
+	if totp == nil {
+		if _, err := a.repo.CreateTotp(ctx, &Totp{UserID: user.ID, PendingSecret: &secret}); err != nil {
+			return "", "", err
+		}
+	} else if err := a.repo.UpdateTotp(ctx, totp, map[string]any{"pending_secret": secret}); err != nil {
+		return "", "", err
+	}
+	return secret, utils.TotpURI(a.cfg.TotpIssuer, totpAccount(user), secret), nil
+}
+
+// ConfirmTotp pending secretni faollashtiradi va yangi recovery kodlar to'plamini qaytaradi.
+// Kodlar faqat shu javobda ochiq ko'rinishda beriladi, bazada hash saqlanadi.
+func (a *AuthUsecaseImpl) ConfirmTotp(ctx context.Context, user *User, code string) ([]string, error) {
+	totp, err := a.repo.GetTotp(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTotpNotEnrolled
+		}
+		return nil, err
+	}
+	if totp.PendingSecret == nil {
+		return nil, ErrTotpNotEnrolled
+	}
+	step, ok := utils.ValidateTotp(*totp.PendingSecret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrInvalidTotpCode
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = a.repo.EnableTotp(ctx, totp, map[string]any{
+		"secret":         *totp.PendingSecret,
+		"pending_secret": nil,
+		"confirmed_at":   time.Now(),
+		"last_used_step": step,
+	}, hashes)
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (a *AuthUsecaseImpl) DisableTotp(ctx context.Context, user *User, code string) error {
+	if err := a.verifyTotpCounted(ctx, user, code); err != nil {
+		return err
+	}
+	totp, err := a.repo.GetTotp(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	a.repo.DeleteTotp(ctx, totp)
+	a.repo.DeleteRecoveryCodes(ctx, user.ID)
+	return nil
+}
+
+// VerifyTotp tasdiqlangan secret bo'yicha kodni tekshiradi. Bir marta ishlatilgan
+// vaqt qadami qayta qabul qilinmaydi.
+func (a *AuthUsecaseImpl) VerifyTotp(ctx context.Context, user *User, code string) error {
+	totp, err := a.repo.GetTotp(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTotpNotEnabled
+		}
+		return err
+	}
+	if !totp.IsEnabled() {
+		return ErrTotpNotEnabled
+	}
+	step, ok := utils.ValidateTotp(*totp.Secret, code, time.Now(), totpSkew)
+	if !ok || !a.repo.UseTotpStep(ctx, user.ID, step) {
+		return ErrInvalidTotpCode
+	}
+	return nil
+}
+
+// VerifySecondFactor MFA bosqichida TOTP kod yoki recovery kodni qabul qiladi. Noto'g'ri
+// kodlar parol bilan bir xil hisoblagichga qo'shiladi, shuning uchun bitta mfa_token bilan
+// kodni cheksiz taxmin qilib bo'lmaydi.
+func (a *AuthUsecaseImpl) VerifySecondFactor(ctx context.Context, user *User, code string) error {
+	if !a.MfaEnabled(ctx, user) {
+		return ErrTotpNotEnabled
+	}
+	if user.IsLocked() {
+		return &LockedError{Until: *user.LockedUntil}
+	}
+	code = strings.TrimSpace(code)
+	if len(code) == utils.TotpDigits {
+		return a.countCodeAttempt(ctx, user, a.VerifyTotp(ctx, user, code))
+	}
+	return a.countCodeAttempt(ctx, user, a.VerifyRecoveryCode(ctx, user, code))
+}
+
+// verifyTotpCounted 2FA ni o'zgartiradigan amallar uchun: access tokeni o'g'irlangan
+// bo'lsa ham kodni lockout ga yetmasdan taxmin qilib bo'lmaydi.
+func (a *AuthUsecaseImpl) verifyTotpCounted(ctx context.Context, user *User, code string) error {
+	if user.IsLocked() {
+		return &LockedError{Until: *user.LockedUntil}
+	}
+	return a.countCodeAttempt(ctx, user, a.VerifyTotp(ctx, user, code))
+}
+
+// countCodeAttempt noto'g'ri kodni parol bilan bir xil hisoblagichga qo'shadi, to'g'ri kod
+// hisoblagichni tozalaydi.
+func (a *AuthUsecaseImpl) countCodeAttempt(ctx context.Context, user *User, err error) error {
+	if errors.Is(err, ErrInvalidTotpCode) || errors.Is(err, ErrInvalidRecoveryCode) {
+		if a.failedLogin(ctx, user) {
+			return &LockedError{Until: *user.LockedUntil}
+		}
+		return err
+	}
+	if err == nil {
+		a.resetFailedLogins(ctx, user)
+	}
+	return err
+}
+
+func (a *AuthUsecaseImpl) VerifyRecoveryCode(ctx context.Context, user *User, code string) error {
+	if !a.repo.UseRecoveryCode(ctx, user.ID, utils.HashToken(normalizeRecoveryCode(code))) {
+		return ErrInvalidRecoveryCode
+	}
+	a.logger.Info("recovery code used", zap.Uint("user_id", user.ID))
+	return nil
+}
+
+func (a *AuthUsecaseImpl) RecoveryCodesRemaining(ctx context.Context, user *User) int64 {
+	return a.repo.CountRecoveryCodes(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes eski kodlarni bekor qiladi. Parol (agar o'rnatilgan bo'lsa) va
+// joriy TOTP kod bilan qayta autentifikatsiya talab qilinadi.
+func (a *AuthUsecaseImpl) RegenerateRecoveryCodes(ctx context.Context, user *User, password string, code string) ([]string, error) {
+	if user.Password != "" {
+		if err := a.checkPassword(ctx, user, password); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.verifyTotpCounted(ctx, user, code); err != nil {
+		return nil, err
+	}
+	return a.generateRecoveryCodes(ctx, user)
+}
+
+func (a *AuthUsecaseImpl) generateRecoveryCodes(ctx context.Context, user *User) ([]string, error) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.repo.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// newRecoveryCodes foydalanuvchiga ko'rsatiladigan kodlar va bazaga yoziladigan hashlari.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		raw, err := utils.SecureRandomString(recoveryCodeLength, recoveryCodeChars)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, raw[:recoveryCodeLength/2]+"-"+raw[recoveryCodeLength/2:])
+		hashes = append(hashes, utils.HashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+func totpAccount(user *User) string {
+	switch {
+	case user.Phone != nil:
+		return *user.Phone
+	case user.Email != nil:
+		return *user.Email
+	default:
+		return fmt.Sprint(user.ID)
+	}
+}
@@ -14,6 +14,14 @@ var (
 	RoleSuper Role = "super"
 )
 
+// OtpChannel tasdiqlash kodlari qaysi kanal orqali yuborilishi. Telegram ishlamasa SMS ga qaytiladi.
+type OtpChannel string
+
+var (
+	OtpChannelSms      OtpChannel = "sms"
+	OtpChannelTelegram OtpChannel = "telegram"
+)
+
 type User struct {
 	gorm.Model
 	FirstName       string     `gorm:"column:first_name"`
@@ -30,6 +38,18 @@ type User struct {
 	Password        string     `gorm:"column:password"`
 	ValidatedAT     *time.Time `gorm:"column:validated_at"`
 	Role            Role       `gorm:"column:role;default:user"`
+	OtpChannel      OtpChannel `gorm:"column:otp_channel;default:sms"`
+	// PasswordResetRequired o'rnatilgan bo'lsa parol bilan kirish /password/reset orqali yangilanguncha bloklanadi.
+	PasswordResetRequired bool `gorm:"column:password_reset_required;default:false"`
+	// SessionsRevokedAt dan oldin berilgan tokenlar qabul qilinmaydi.
+	SessionsRevokedAt *time.Time `gorm:"column:sessions_revoked_at"`
+	SuspendedUntil    *time.Time `gorm:"column:suspended_until"`
+	SuspensionReason  string     `gorm:"column:suspension_reason"`
+	// FailedLoginAttempts ketma-ket noto'g'ri parollar, LockoutCount esa bloklashlar soni
+	// (keyingi bloklash muddatini hisoblash uchun). Ikkalasi muvaffaqiyatli kirishda nollanadi.
+	FailedLoginAttempts int        `gorm:"column:failed_login_attempts;default:0"`
+	LockoutCount        int        `gorm:"column:lockout_count;default:0"`
+	LockedUntil         *time.Time `gorm:"column:locked_until"`
 }
 
 func (*User) TableName() string {
@@ -46,3 +66,112 @@ type Otp struct {
 func (*Otp) TableName() string {
 	return "otp"
 }
+
+func (u *User) IsSuspended() bool {
+	return u.SuspendedUntil != nil && time.Now().Before(*u.SuspendedUntil)
+}
+
+func (u *User) IsLocked() bool {
+	return u.LockedUntil != nil && time.Now().Before(*u.LockedUntil)
+}
+
+func (u *User) IsPrivileged() bool {
+	return u.Role == RoleAdmin || u.Role == RoleSuper || (u.IsStaff != nil && *u.IsStaff)
+}
+
+type Totp struct {
+	gorm.Model
+	UserID        uint       `gorm:"column:user_id;uniqueIndex"`
+	Secret        *string    `gorm:"column:secret;default:null"`
+	PendingSecret *string    `gorm:"column:pending_secret;default:null"`
+	ConfirmedAt   *time.Time `gorm:"column:confirmed_at"`
+	LastUsedStep  int64      `gorm:"column:last_used_step;default:0"`
+}
+
+func (*Totp) TableName() string {
+	return "totp"
+}
+
+func (t *Totp) IsEnabled() bool {
+	return t.ConfirmedAt != nil && t.Secret != nil
+}
+
+type RecoveryCode struct {
+	gorm.Model
+	UserID   uint       `gorm:"column:user_id;index"`
+	CodeHash string     `gorm:"column:code_hash;uniqueIndex"`
+	UsedAt   *time.Time `gorm:"column:used_at"`
+}
+
+func (*RecoveryCode) TableName() string {
+	return "recovery_code"
+}
+
+type MagicLink struct {
+	gorm.Model
+	UserID uint       `gorm:"column:user_id;index"`
+	Jti    string     `gorm:"column:jti;uniqueIndex"`
+	Exp    time.Time  `gorm:"column:exp"`
+	UsedAt *time.Time `gorm:"column:used_at"`
+}
+
+func (*MagicLink) TableName() string {
+	return "magic_link"
+}
+
+// Device foydalanuvchi kirgan qurilma: brauzer/OS oilasi, IP /24 tarmog'i va mamlakat.
+// Tokenlardagi sid shu yozuvga ishora qiladi, RevokedAt o'rnatilgach ular qabul qilinmaydi.
+// Bekor qilingan qurilmadan qayta kirilsa yangi yozuv yaratiladi.
+type Device struct {
+	gorm.Model
+	UserID      uint       `gorm:"column:user_id;index"`
+	Fingerprint string     `gorm:"column:fingerprint;index"`
+	Family      string     `gorm:"column:family"`
+	Network     string     `gorm:"column:network"`
+	Country     string     `gorm:"column:country"`
+	UserAgent   string     `gorm:"column:user_agent"`
+	LastIP      string     `gorm:"column:last_ip"`
+	LastSeenAt  time.Time  `gorm:"column:last_seen_at"`
+	RevokeToken string     `gorm:"column:revoke_token;index"`
+	RevokedAt   *time.Time `gorm:"column:revoked_at"`
+}
+
+func (*Device) TableName() string {
+	return "auth_device"
+}
+
+// ExternalIdentity foydalanuvchini tashqi provider akkaunti (provider, sub) bilan bog'laydi.
+type ExternalIdentity struct {
+	gorm.Model
+	UserID        uint       `gorm:"column:user_id;index"`
+	Provider      string     `gorm:"column:provider;uniqueIndex:idx_external_identity_provider_subject"`
+	Subject       string     `gorm:"column:subject;uniqueIndex:idx_external_identity_provider_subject"`
+	Email         *string    `gorm:"column:email;default:null"`
+	EmailVerified bool       `gorm:"column:email_verified"`
+	LastLoginAt   *time.Time `gorm:"column:last_login_at"`
+}
+
+func (*ExternalIdentity) TableName() string {
+	return "external_identity"
+}
+
+type Permission struct {
+	gorm.Model
+	Code        string `gorm:"column:code;uniqueIndex"`
+	Description string `gorm:"column:description"`
+}
+
+func (*Permission) TableName() string {
+	return "permission"
+}
+
+type RolePermission struct {
+	gorm.Model
+	Role         Role       `gorm:"column:role;uniqueIndex:idx_role_permission"`
+	PermissionID uint       `gorm:"column:permission_id;uniqueIndex:idx_role_permission"`
+	Permission   Permission `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (*RolePermission) TableName() string {
+	return "role_permission"
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/JscorpTech/auth/internal/modules/audit"
+	"github.com/JscorpTech/auth/internal/password"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RequestPasswordReset telefon raqamga OTP yuboradi. Raqam ro'yxatdan o'tmagan bo'lsa
+// ham xato qaytarilmaydi.
+func (a *AuthUsecaseImpl) RequestPasswordReset(ctx context.Context, phone string) error {
+	phone, err := a.phones.Normalize(phone)
+	if err != nil {
+		return err
+	}
+	if _, err := a.repo.GetByPhone(ctx, phone); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return a.SendOtp(ctx, phone)
+}
+
+// ResetPassword yangi parolni o'rnatadi va barcha sessiyalarni bekor qiladi. OTP dan oldin
+// faqat akkauntga bog'liq bo'lmagan qoidalar (uzunlik, belgilar, breached) tekshiriladi, shunda
+// zaif parolda kod behuda sarflanmaydi, lekin endpoint akkaunt borligini yoki egasining
+// ismi, emailini tasdiqlab bermaydi. personal_info OTP to'g'ri bo'lgandan keyin tekshiriladi.
+func (a *AuthUsecaseImpl) ResetPassword(ctx context.Context, phone string, otp string, plain string) error {
+	phone, err := a.phones.Normalize(phone)
+	if err != nil {
+		return ErrInvalidOtp
+	}
+	if err := a.policy.Check(plain, password.Identity{}); err != nil {
+		return err
+	}
+	if !a.ValidateOtp(ctx, phone, otp) {
+		return ErrInvalidOtp
+	}
+	user, err := a.repo.GetByPhone(ctx, phone)
+	if err != nil {
+		return ErrInvalidOtp
+	}
+	if err := a.ValidatePassword(plain, user); err != nil {
+		return err
+	}
+	return a.setPassword(ctx, user, plain, "reset")
+}
+
+// ChangePassword joriy parolni tekshirib yangisini o'rnatadi, boshqa sessiyalar bekor bo'ladi.
+func (a *AuthUsecaseImpl) ChangePassword(ctx context.Context, user *User, current string, plain string) error {
+	if err := a.checkPassword(ctx, user, current); err != nil {
+		return err
+	}
+	if err := a.ValidatePassword(plain, user); err != nil {
+		return err
+	}
+	return a.setPassword(ctx, user, plain, "change")
+}
+
+// ValidatePassword parol siyosatini tekshiradi, buzilgan qoidalar *password.PolicyError da bo'ladi.
+func (a *AuthUsecaseImpl) ValidatePassword(plain string, user *User) error {
+	identity := password.Identity{
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+	}
+	if user.Phone != nil {
+		identity.Phone = *user.Phone
+	}
+	if user.Email != nil {
+		identity.Email = *user.Email
+	}
+	return a.policy.Check(plain, identity)
+}
+
+func (a *AuthUsecaseImpl) HashPassword(plain string) (string, error) {
+	return a.hasher.Hash(plain)
+}
+
+// checkPassword login, reauth va parol almashtirishda parolni bloklash hisoblagichi bilan
+// tekshiradi: bloklangan akkauntda parol umuman tekshirilmaydi, noto'g'ri urinishlar esa
+// o'g'irlangan access token bilan ham bloklashga olib keladi.
+func (a *AuthUsecaseImpl) checkPassword(ctx context.Context, user *User, plain string) error {
+	if user.IsLocked() {
+		return &LockedError{Until: *user.LockedUntil}
+	}
+	if !a.verifyPassword(ctx, user, plain) {
+		if a.failedLogin(ctx, user) {
+			return &LockedError{Until: *user.LockedUntil}
+		}
+		return ErrInvalidPassword
+	}
+	a.resetFailedLogins(ctx, user)
+	return nil
+}
+
+// verifyPassword parolni tekshiradi va to'g'ri bo'lsa eskirgan algoritm yoki kuchsiz
+// parametrli hashni joriy sozlama bilan qayta hashlaydi. Parolsiz (social) akkauntda false.
+func (a *AuthUsecaseImpl) verifyPassword(ctx context.Context, user *User, plain string) bool {
+	if user.Password == "" {
+		return false
+	}
+	ok, err := a.hasher.Verify(plain, user.Password)
+	if err != nil {
+		a.logger.Error("password verify error", zap.Uint("user_id", user.ID), zap.Error(err))
+		return false
+	}
+	if ok && a.hasher.NeedsRehash(user.Password) {
+		hash, err := a.hasher.Hash(plain)
+		if err != nil {
+			a.logger.Error("password rehash error", zap.Uint("user_id", user.ID), zap.Error(err))
+			return ok
+		}
+		a.repo.Update(ctx, user, map[string]any{"password": hash})
+		user.Password = hash
+	}
+	return ok
+}
+
+func (a *AuthUsecaseImpl) setPassword(ctx context.Context, user *User, plain string, method string) error {
+	hash, err := a.hasher.Hash(plain)
+	if err != nil {
+		return err
+	}
+	a.repo.Update(ctx, user, map[string]any{
+		"password":                hash,
+		"password_reset_required": false,
+		"failed_login_attempts":   0,
+		"lockout_count":           0,
+		"locked_until":            nil,
+	})
+	user.Password = hash
+	user.PasswordResetRequired = false
+	a.record(ctx, audit.TypePasswordChanged, user, map[string]any{"method": method})
+	a.RevokeSessions(ctx, user)
+	return nil
+}
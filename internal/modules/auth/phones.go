@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/JscorpTech/auth/internal/phone"
+)
+
+type PhoneChange struct {
+	UserID uint
+	From   string
+	To     string
+}
+
+// PhoneMigrationReport Collisions da bitta raqamga to'g'ri keladigan foydalanuvchilar,
+// ular o'zgartirilmaydi va qo'lda birlashtirilishi kerak.
+type PhoneMigrationReport struct {
+	Total      int
+	Changes    []PhoneChange
+	Invalid    []PhoneChange
+	Collisions map[string][]PhoneChange
+}
+
+// MigratePhones mavjud raqamlarni E.164 ga keltiradi. apply false bo'lsa baza o'zgartirilmaydi,
+// faqat hisobot qaytadi.
+func MigratePhones(ctx context.Context, repo AuthRepository, phones phone.Parser, apply bool) (*PhoneMigrationReport, error) {
+	users, err := repo.GetPhones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &PhoneMigrationReport{Total: len(users), Collisions: map[string][]PhoneChange{}}
+	groups := map[string][]PhoneChange{}
+	var order []string
+	for _, user := range users {
+		normalized, err := phones.Normalize(*user.Phone)
+		if err != nil {
+			report.Invalid = append(report.Invalid, PhoneChange{UserID: user.ID, From: *user.Phone})
+			continue
+		}
+		if _, ok := groups[normalized]; !ok {
+			order = append(order, normalized)
+		}
+		groups[normalized] = append(groups[normalized], PhoneChange{UserID: user.ID, From: *user.Phone, To: normalized})
+	}
+	updates := map[uint]string{}
+	for _, normalized := range order {
+		group := groups[normalized]
+		if len(group) > 1 {
+			report.Collisions[normalized] = group
+			continue
+		}
+		if group[0].From != normalized {
+			report.Changes = append(report.Changes, group[0])
+			updates[group[0].UserID] = normalized
+		}
+	}
+	if apply && len(updates) > 0 {
+		if err := repo.UpdatePhones(ctx, updates); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/modules/audit"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// PermissionAll super rolga beriladi va istalgan ruxsatni qoplaydi.
+	PermissionAll    = "*"
+	PermUsersRead    = "users:read"
+	PermUsersWrite   = "users:write"
+	PermRolesManage  = "roles:manage"
+	PermImpersonate  = "users:impersonate"
+	PermAuditRead    = "audit:read"
+	PermSmsManage    = "sms:manage"
+	permissionsCache = time.Minute
+)
+
+// defaultPermissions ishga tushishda yaratiladi. Yangi yaratilgan ruxsat ro'yxatdagi
+// rollarga ham beriladi, keyin admin o'zgartirgan sozlamalarga tegilmaydi.
+var defaultPermissions = []struct {
+	Code        string
+	Description string
+	Roles       []Role
+}{
+	{PermUsersRead, "View users", []Role{RoleAdmin}},
+	{PermUsersWrite, "Manage users", []Role{RoleAdmin}},
+	{PermRolesManage, "Manage roles and permissions", nil},
+	{PermImpersonate, "Sign in as another user", []Role{RoleAdmin}},
+	{PermAuditRead, "View security audit log", []Role{RoleAdmin}},
+	{PermSmsManage, "View SMS statistics and manage send limits", []Role{RoleAdmin}},
+}
+
+// permissionCache rol ruxsatlarini access token yaratishda har safar bazaga murojaat
+// qilmaslik uchun saqlaydi. O'zgartirilganda shu instansda darhol, boshqalarida TTL dan keyin yangilanadi.
+type permissionCache struct {
+	mu       sync.RWMutex
+	roles    map[Role][]string
+	loadedAt time.Time
+}
+
+func (a *AuthUsecaseImpl) SeedPermissions(ctx context.Context) error {
+	for _, item := range defaultPermissions {
+		_, err := a.repo.GetPermission(ctx, item.Code)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		permission, err := a.repo.CreatePermission(ctx, &Permission{Code: item.Code, Description: item.Description})
+		if err != nil {
+			return err
+		}
+		for _, role := range item.Roles {
+			if err := a.repo.AddRolePermission(ctx, role, permission.ID); err != nil {
+				return err
+			}
+		}
+	}
+	a.invalidatePermissions()
+	return nil
+}
+
+func (a *AuthUsecaseImpl) RolePermissions(ctx context.Context, role Role) []string {
+	if role == RoleSuper {
+		return []string{PermissionAll}
+	}
+	a.permissions.mu.RLock()
+	if a.permissions.roles != nil && time.Since(a.permissions.loadedAt) < permissionsCache {
+		permissions := a.permissions.roles[role]
+		a.permissions.mu.RUnlock()
+		return permissionsOrEmpty(permissions)
+	}
+	a.permissions.mu.RUnlock()
+
+	roles, err := a.loadRoles(ctx)
+	if err != nil {
+		a.logger.Error("load role permissions error", zap.Error(err))
+		return []string{}
+	}
+	a.permissions.mu.Lock()
+	a.permissions.roles = roles
+	a.permissions.loadedAt = time.Now()
+	a.permissions.mu.Unlock()
+	return permissionsOrEmpty(roles[role])
+}
+
+func (a *AuthUsecaseImpl) loadRoles(ctx context.Context) (map[Role][]string, error) {
+	rolePermissions, err := a.repo.GetRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roles := map[Role][]string{}
+	for _, item := range rolePermissions {
+		roles[item.Role] = append(roles[item.Role], item.Permission.Code)
+	}
+	for role := range roles {
+		slices.Sort(roles[role])
+	}
+	return roles, nil
+}
+
+func (a *AuthUsecaseImpl) invalidatePermissions() {
+	a.permissions.mu.Lock()
+	a.permissions.roles = nil
+	a.permissions.mu.Unlock()
+}
+
+func (a *AuthUsecaseImpl) ListPermissions(ctx context.Context) ([]Permission, error) {
+	return a.repo.GetPermissions(ctx)
+}
+
+func (a *AuthUsecaseImpl) CreatePermission(ctx context.Context, code string, description string) (*Permission, error) {
+	if code == PermissionAll {
+		return nil, ErrPermissionExists
+	}
+	if _, err := a.repo.GetPermission(ctx, code); err == nil {
+		return nil, ErrPermissionExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	permission, err := a.repo.CreatePermission(ctx, &Permission{Code: code, Description: description})
+	if err != nil {
+		return nil, err
+	}
+	a.record(ctx, audit.TypePermissionCreated, nil, map[string]any{"code": code})
+	return permission, nil
+}
+
+func (a *AuthUsecaseImpl) DeletePermission(ctx context.Context, code string) error {
+	permission, err := a.repo.GetPermission(ctx, code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPermissionNotFound
+		}
+		return err
+	}
+	if err := a.repo.DeletePermission(ctx, permission); err != nil {
+		return err
+	}
+	a.invalidatePermissions()
+	a.record(ctx, audit.TypePermissionDeleted, nil, map[string]any{"code": code})
+	return nil
+}
+
+// ListRoles o'rnatilgan rollarni va ruxsati bor boshqa rollarni qaytaradi.
+func (a *AuthUsecaseImpl) ListRoles(ctx context.Context) (map[Role][]string, error) {
+	roles, err := a.loadRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range []Role{RoleUser, RoleAdmin} {
+		roles[role] = permissionsOrEmpty(roles[role])
+	}
+	roles[RoleSuper] = []string{PermissionAll}
+	return roles, nil
+}
+
+func (a *AuthUsecaseImpl) SetRolePermissions(ctx context.Context, role Role, codes []string) ([]string, error) {
+	if role == RoleSuper {
+		return nil, ErrRoleImmutable
+	}
+	codes = slices.Compact(slices.Sorted(slices.Values(codes)))
+	permissions, err := a.repo.GetPermissionsByCode(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	if len(permissions) != len(codes) {
+		return nil, ErrPermissionNotFound
+	}
+	ids := make([]uint, 0, len(permissions))
+	for _, permission := range permissions {
+		ids = append(ids, permission.ID)
+	}
+	if err := a.repo.ReplaceRolePermissions(ctx, role, ids); err != nil {
+		return nil, err
+	}
+	a.invalidatePermissions()
+	a.record(ctx, audit.TypeRolePermissionsChange, nil, map[string]any{"role": role, "permissions": codes})
+	return codes, nil
+}
+
+func permissionsOrEmpty(permissions []string) []string {
+	if permissions == nil {
+		return []string{}
+	}
+	return permissions
+}
+
+func PermissionsFromClaims(claims jwt.MapClaims) []string {
+	var permissions []string
+	if values, ok := claims["permissions"].([]any); ok {
+		for _, value := range values {
+			if permission, ok := value.(string); ok {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+func HasPermission(permissions []string, permission string) bool {
+	return slices.Contains(permissions, PermissionAll) || slices.Contains(permissions, permission)
+}
@@ -2,14 +2,26 @@ package auth
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// UserFilter admin ro'yxati uchun filtrlar, nil maydonlar e'tiborga olinmaydi.
+type UserFilter struct {
+	Search    string
+	Role      *Role
+	IsActive  *bool
+	Confirmed *bool
+	Offset    int
+	Limit     int
+}
+
 type AuthRepository interface {
 	GetID(context.Context, int64) (*User, error)
 	Update(context.Context, *User, map[string]any)
+	IncrementFailedLogins(context.Context, *User) (int, error)
 	Create(context.Context, *User) (*User, error)
 	IsExists(context.Context, string) bool
 	GetByPhone(context.Context, string) (*User, error)
@@ -20,6 +32,45 @@ type AuthRepository interface {
 	GetOtpByPhone(context.Context, string) (*Otp, error)
 	UpdateOtp(context.Context, string, string) error
 	GetOldOtps(context.Context) ([]Otp, error)
+	GetTotp(context.Context, uint) (*Totp, error)
+	CreateTotp(context.Context, *Totp) (*Totp, error)
+	UpdateTotp(context.Context, *Totp, map[string]any) error
+	DeleteTotp(context.Context, *Totp)
+	ReplaceRecoveryCodes(context.Context, uint, []string) error
+	EnableTotp(context.Context, *Totp, map[string]any, []string) error
+	UseTotpStep(context.Context, uint, int64) bool
+	UseRecoveryCode(context.Context, uint, string) bool
+	CountRecoveryCodes(context.Context, uint) int64
+	DeleteRecoveryCodes(context.Context, uint)
+	CreateMagicLink(context.Context, *MagicLink) (*MagicLink, error)
+	GetLastMagicLink(context.Context, uint) (*MagicLink, error)
+	UseMagicLink(context.Context, string) bool
+	DeleteExpiredMagicLinks(context.Context)
+	GetExternalIdentity(context.Context, string, string) (*ExternalIdentity, error)
+	GetUserExternalIdentity(context.Context, uint, string) (*ExternalIdentity, error)
+	GetExternalIdentities(context.Context, uint) ([]ExternalIdentity, error)
+	CreateExternalIdentity(context.Context, *ExternalIdentity) (*ExternalIdentity, error)
+	UpdateExternalIdentity(context.Context, *ExternalIdentity, map[string]any) error
+	DeleteExternalIdentity(context.Context, *ExternalIdentity)
+	GetPermissions(context.Context) ([]Permission, error)
+	GetPermission(context.Context, string) (*Permission, error)
+	GetPermissionsByCode(context.Context, []string) ([]Permission, error)
+	CreatePermission(context.Context, *Permission) (*Permission, error)
+	DeletePermission(context.Context, *Permission) error
+	GetRolePermissions(context.Context) ([]RolePermission, error)
+	AddRolePermission(context.Context, Role, uint) error
+	ReplaceRolePermissions(context.Context, Role, []uint) error
+	ListUsers(context.Context, UserFilter) ([]User, int64, error)
+	GetPhones(context.Context) ([]User, error)
+	CountPasskeys(context.Context, uint) int64
+	GetDevice(context.Context, uint, string) (*Device, error)
+	GetDeviceByID(context.Context, uint) (*Device, error)
+	GetDeviceByRevokeToken(context.Context, string) (*Device, error)
+	GetDevices(context.Context, uint) ([]Device, error)
+	CountDevices(context.Context, uint) int64
+	CreateDevice(context.Context, *Device) (*Device, error)
+	UpdateDevice(context.Context, *Device, map[string]any) error
+	UpdatePhones(context.Context, map[uint]string) error
 }
 
 type AuthRepositoryImpl struct {
@@ -73,6 +124,18 @@ func (a *AuthRepositoryImpl) Update(ctx context.Context, user *User, update map[
 	a.db.WithContext(ctx).Model(user).Updates(update)
 }
 
+// IncrementFailedLogins hisoblagichni bazada oshiradi, parallel urinishlar ham sanaladi.
+func (a *AuthRepositoryImpl) IncrementFailedLogins(ctx context.Context, user *User) (int, error) {
+	var attempts int
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&User{}).Where("id = ?", user.ID).UpdateColumn("failed_login_attempts", gorm.Expr("failed_login_attempts + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Model(&User{}).Where("id = ?", user.ID).Select("failed_login_attempts").Scan(&attempts).Error
+	})
+	return attempts, err
+}
+
 func (a *AuthRepositoryImpl) GetOtp(ctx context.Context, phone string, otp string) (*Otp, error) {
 	var otpInstance Otp
 	if err := a.db.WithContext(ctx).Where("phone = ? and code = ?", phone, otp).First(&otpInstance).Error; err != nil {
@@ -120,3 +183,327 @@ func (a *AuthRepositoryImpl) GetOldOtps(ctx context.Context) ([]Otp, error) {
 	}
 	return otps, nil
 }
+
+func (a *AuthRepositoryImpl) GetTotp(ctx context.Context, userID uint) (*Totp, error) {
+	var totp Totp
+	if err := a.db.WithContext(ctx).Where("user_id = ?", userID).First(&totp).Error; err != nil {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+func (a *AuthRepositoryImpl) CreateTotp(ctx context.Context, totp *Totp) (*Totp, error) {
+	if err := a.db.WithContext(ctx).Create(totp).Error; err != nil {
+		return nil, err
+	}
+	return totp, nil
+}
+
+func (a *AuthRepositoryImpl) UpdateTotp(ctx context.Context, totp *Totp, update map[string]any) error {
+	return a.db.WithContext(ctx).Model(totp).Updates(update).Error
+}
+
+func (a *AuthRepositoryImpl) DeleteTotp(ctx context.Context, totp *Totp) {
+	a.db.WithContext(ctx).Unscoped().Delete(totp)
+}
+
+func (a *AuthRepositoryImpl) ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error {
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, hashes)
+	})
+}
+
+// EnableTotp TOTP ni yoqish va recovery kodlarni saqlashni bitta tranzaksiyada bajaradi,
+// shunda 2FA recovery kodlarsiz yoqilib qolmaydi.
+func (a *AuthRepositoryImpl) EnableTotp(ctx context.Context, totp *Totp, update map[string]any, hashes []string) error {
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := replaceRecoveryCodes(tx, totp.UserID, hashes); err != nil {
+			return err
+		}
+		return tx.Model(totp).Updates(update).Error
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, hashes []string) error {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]RecoveryCode, 0, len(hashes))
+	for _, hash := range hashes {
+		codes = append(codes, RecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return tx.Create(&codes).Error
+}
+
+// UseTotpStep vaqt qadamini atomik ravishda ishlatilgan deb belgilaydi, parallel so'rovlarda
+// bitta kod faqat bir marta qabul qilinadi.
+func (a *AuthRepositoryImpl) UseTotpStep(ctx context.Context, userID uint, step int64) bool {
+	res := a.db.WithContext(ctx).Model(&Totp{}).
+		Where("user_id = ? and last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return res.Error == nil && res.RowsAffected == 1
+}
+
+// UseRecoveryCode kodni atomik ravishda ishlatilgan deb belgilaydi, parallel so'rovlarda
+// bitta kod faqat bir marta qabul qilinadi.
+func (a *AuthRepositoryImpl) UseRecoveryCode(ctx context.Context, userID uint, hash string) bool {
+	res := a.db.WithContext(ctx).Model(&RecoveryCode{}).
+		Where("user_id = ? and code_hash = ? and used_at is null", userID, hash).
+		Update("used_at", time.Now())
+	return res.Error == nil && res.RowsAffected == 1
+}
+
+func (a *AuthRepositoryImpl) CountRecoveryCodes(ctx context.Context, userID uint) int64 {
+	var count int64
+	a.db.WithContext(ctx).Model(&RecoveryCode{}).Where("user_id = ? and used_at is null", userID).Count(&count)
+	return count
+}
+
+func (a *AuthRepositoryImpl) DeleteRecoveryCodes(ctx context.Context, userID uint) {
+	a.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&RecoveryCode{})
+}
+
+func (a *AuthRepositoryImpl) CreateMagicLink(ctx context.Context, link *MagicLink) (*MagicLink, error) {
+	if err := a.db.WithContext(ctx).Create(link).Error; err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (a *AuthRepositoryImpl) GetLastMagicLink(ctx context.Context, userID uint) (*MagicLink, error) {
+	var link MagicLink
+	if err := a.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at desc").First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (a *AuthRepositoryImpl) UseMagicLink(ctx context.Context, jti string) bool {
+	res := a.db.WithContext(ctx).Model(&MagicLink{}).
+		Where("jti = ? and used_at is null and exp > ?", jti, time.Now()).
+		Update("used_at", time.Now())
+	return res.Error == nil && res.RowsAffected == 1
+}
+
+func (a *AuthRepositoryImpl) DeleteExpiredMagicLinks(ctx context.Context) {
+	a.db.WithContext(ctx).Unscoped().Where("exp <= ?", time.Now()).Delete(&MagicLink{})
+}
+
+func (a *AuthRepositoryImpl) GetExternalIdentity(ctx context.Context, provider string, subject string) (*ExternalIdentity, error) {
+	var identity ExternalIdentity
+	if err := a.db.WithContext(ctx).Where("provider = ? and subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (a *AuthRepositoryImpl) GetUserExternalIdentity(ctx context.Context, userID uint, provider string) (*ExternalIdentity, error) {
+	var identity ExternalIdentity
+	if err := a.db.WithContext(ctx).Where("user_id = ? and provider = ?", userID, provider).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (a *AuthRepositoryImpl) GetExternalIdentities(ctx context.Context, userID uint) ([]ExternalIdentity, error) {
+	var identities []ExternalIdentity
+	if err := a.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (a *AuthRepositoryImpl) CreateExternalIdentity(ctx context.Context, identity *ExternalIdentity) (*ExternalIdentity, error) {
+	if err := a.db.WithContext(ctx).Create(identity).Error; err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (a *AuthRepositoryImpl) UpdateExternalIdentity(ctx context.Context, identity *ExternalIdentity, update map[string]any) error {
+	return a.db.WithContext(ctx).Model(identity).Updates(update).Error
+}
+
+func (a *AuthRepositoryImpl) DeleteExternalIdentity(ctx context.Context, identity *ExternalIdentity) {
+	a.db.WithContext(ctx).Unscoped().Delete(identity)
+}
+
+func (a *AuthRepositoryImpl) GetPermissions(ctx context.Context) ([]Permission, error) {
+	var permissions []Permission
+	if err := a.db.WithContext(ctx).Order("code").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (a *AuthRepositoryImpl) GetPermission(ctx context.Context, code string) (*Permission, error) {
+	var permission Permission
+	if err := a.db.WithContext(ctx).Where("code = ?", code).First(&permission).Error; err != nil {
+		return nil, err
+	}
+	return &permission, nil
+}
+
+func (a *AuthRepositoryImpl) GetPermissionsByCode(ctx context.Context, codes []string) ([]Permission, error) {
+	var permissions []Permission
+	if err := a.db.WithContext(ctx).Where("code in ?", codes).Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (a *AuthRepositoryImpl) CreatePermission(ctx context.Context, permission *Permission) (*Permission, error) {
+	if err := a.db.WithContext(ctx).Create(permission).Error; err != nil {
+		return nil, err
+	}
+	return permission, nil
+}
+
+func (a *AuthRepositoryImpl) DeletePermission(ctx context.Context, permission *Permission) error {
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("permission_id = ?", permission.ID).Delete(&RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(permission).Error
+	})
+}
+
+func (a *AuthRepositoryImpl) GetRolePermissions(ctx context.Context) ([]RolePermission, error) {
+	var rolePermissions []RolePermission
+	if err := a.db.WithContext(ctx).Preload("Permission").Order("role").Find(&rolePermissions).Error; err != nil {
+		return nil, err
+	}
+	return rolePermissions, nil
+}
+
+func (a *AuthRepositoryImpl) AddRolePermission(ctx context.Context, role Role, permissionID uint) error {
+	return a.db.WithContext(ctx).
+		Where(RolePermission{Role: role, PermissionID: permissionID}).
+		FirstOrCreate(&RolePermission{}).Error
+}
+
+// ReplaceRolePermissions rol ruxsatlarini berilgan to'plam bilan almashtiradi.
+func (a *AuthRepositoryImpl) ReplaceRolePermissions(ctx context.Context, role Role, permissionIDs []uint) error {
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("role = ?", role).Delete(&RolePermission{}).Error; err != nil {
+			return err
+		}
+		for _, id := range permissionIDs {
+			if err := tx.Create(&RolePermission{Role: role, PermissionID: id}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (a *AuthRepositoryImpl) ListUsers(ctx context.Context, filter UserFilter) ([]User, int64, error) {
+	query := a.db.WithContext(ctx).Model(&User{})
+	if filter.Search != "" {
+		like := "%" + strings.ToLower(filter.Search) + "%"
+		query = query.Where(
+			"lower(phone) like ? or lower(email) like ? or lower(first_name) like ? or lower(last_name) like ? or lower(first_name || ' ' || last_name) like ?",
+			like, like, like, like, like,
+		)
+	}
+	if filter.Role != nil {
+		query = query.Where("role = ?", *filter.Role)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.Confirmed != nil {
+		if *filter.Confirmed {
+			query = query.Where("validated_at is not null")
+		} else {
+			query = query.Where("validated_at is null")
+		}
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []User
+	if err := query.Order("id desc").Offset(filter.Offset).Limit(filter.Limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// GetPhones o'chirilganlar bilan birga raqami bor barcha foydalanuvchilar, unique index
+// o'chirilgan qatorlarga ham amal qiladi.
+func (a *AuthRepositoryImpl) GetPhones(ctx context.Context) ([]User, error) {
+	var users []User
+	err := a.db.WithContext(ctx).Unscoped().Select("id", "phone").Where("phone IS NOT NULL").Order("id").Find(&users).Error
+	return users, err
+}
+
+// UpdatePhones barcha raqamlarni bitta tranzaksiyada yangilaydi.
+func (a *AuthRepositoryImpl) UpdatePhones(ctx context.Context, phones map[uint]string) error {
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for id, phone := range phones {
+			if err := tx.Unscoped().Model(&User{}).Where("id = ?", id).Update("phone", phone).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetDevice foydalanuvchining shu fingerprint li bekor qilinmagan qurilmasi.
+func (a *AuthRepositoryImpl) GetDevice(ctx context.Context, userID uint, fingerprint string) (*Device, error) {
+	var device Device
+	err := a.db.WithContext(ctx).
+		Where("user_id = ? and fingerprint = ? and revoked_at is null", userID, fingerprint).
+		First(&device).Error
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (a *AuthRepositoryImpl) GetDeviceByID(ctx context.Context, id uint) (*Device, error) {
+	var device Device
+	if err := a.db.WithContext(ctx).First(&device, id).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (a *AuthRepositoryImpl) GetDeviceByRevokeToken(ctx context.Context, token string) (*Device, error) {
+	var device Device
+	if err := a.db.WithContext(ctx).Where("revoke_token = ?", token).First(&device).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (a *AuthRepositoryImpl) GetDevices(ctx context.Context, userID uint) ([]Device, error) {
+	var devices []Device
+	err := a.db.WithContext(ctx).Where("user_id = ?", userID).Order("last_seen_at desc").Find(&devices).Error
+	return devices, err
+}
+
+func (a *AuthRepositoryImpl) CountDevices(ctx context.Context, userID uint) int64 {
+	var count int64
+	a.db.WithContext(ctx).Model(&Device{}).Where("user_id = ?", userID).Count(&count)
+	return count
+}
+
+func (a *AuthRepositoryImpl) CreateDevice(ctx context.Context, device *Device) (*Device, error) {
+	if err := a.db.WithContext(ctx).Create(device).Error; err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (a *AuthRepositoryImpl) UpdateDevice(ctx context.Context, device *Device, data map[string]any) error {
+	return a.db.WithContext(ctx).Model(device).Updates(data).Error
+}
+
+// CountPasskeys passkey moduli auth ni import qiladi, shuning uchun jadval nomi bo'yicha sanaladi.
+func (a *AuthRepositoryImpl) CountPasskeys(ctx context.Context, userID uint) int64 {
+	var count int64
+	a.db.WithContext(ctx).Table("webauthn_credential").Where("user_id = ? and deleted_at is null", userID).Count(&count)
+	return count
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/social"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func (a *AuthUsecaseImpl) verifyIdentity(ctx context.Context, providerName string, token string) (*social.Identity, error) {
+	provider, ok := a.providers[providerName]
+	if !ok {
+		return nil, social.ErrUnknownProvider
+	}
+	identity, err := provider.Verify(ctx, token)
+	if err != nil {
+		a.logger.Info("social token verify error", zap.String("provider", providerName), zap.Error(err))
+		return nil, social.ErrInvalidToken
+	}
+	if identity.Subject == "" {
+		return nil, social.ErrInvalidToken
+	}
+	return identity, nil
+}
+
+// SocialAuth foydalanuvchini avval (provider, sub) bo'yicha topadi. Bog'lanmagan
+// identity uchun provider tasdiqlagan email bo'yicha mavjud akkauntga bog'laydi yoki
+// yangi foydalanuvchi yaratadi. Tasdiqlanmagan email bilan hech narsa bog'lanmaydi.
+func (a *AuthUsecaseImpl) SocialAuth(ctx context.Context, providerName string, token string) (*User, error) {
+	identity, err := a.verifyIdentity(ctx, providerName, token)
+	if err != nil {
+		return nil, err
+	}
+	linked, err := a.repo.GetExternalIdentity(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		user, err := a.linkedUser(ctx, linked)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.repo.UpdateExternalIdentity(ctx, linked, identityUpdate(identity)); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" {
+		return nil, ErrEmailNotProvided
+	}
+	if !identity.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+	email := identity.Email
+	userInstance, err := a.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			now := time.Now()
+			isSuperUser := false
+			isStaff := false
+			isActive := true
+			dateJoined := time.Now()
+			user := &User{
+				Email:       &email,
+				FirstName:   identity.FirstName,
+				LastName:    identity.LastName,
+				IsSuperuser: &isSuperUser,
+				IsStaff:     &isStaff,
+				IsActive:    &isActive,
+				DateJoined:  &dateJoined,
+				ValidatedAT: &now,
+			}
+			if userInstance, err = a.repo.Create(ctx, user); err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, err
+		}
+	}
+	if err := a.CheckStatus(userInstance); err != nil {
+		return nil, err
+	}
+	if _, err := a.createIdentity(ctx, userInstance, identity); err != nil {
+		return nil, err
+	}
+	return userInstance, nil
+}
+
+// linkedUser identity egasini yuklaydi. Egasi soft-delete qilingan bo'lsa akkaunt
+// o'chirilgan hisoblanadi, yangi akkaunt yaratilmaydi.
+func (a *AuthUsecaseImpl) linkedUser(ctx context.Context, linked *ExternalIdentity) (*User, error) {
+	user, err := a.repo.GetID(ctx, int64(linked.UserID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccountDisabled
+		}
+		return nil, err
+	}
+	if err := a.CheckStatus(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// LinkIdentity tizimga kirgan foydalanuvchiga tashqi akkauntni bog'laydi. Bitta
+// provider bo'yicha foydalanuvchida faqat bitta identity bo'ladi.
+func (a *AuthUsecaseImpl) LinkIdentity(ctx context.Context, user *User, providerName string, token string) (*ExternalIdentity, error) {
+	identity, err := a.verifyIdentity(ctx, providerName, token)
+	if err != nil {
+		return nil, err
+	}
+	linked, err := a.repo.GetExternalIdentity(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		if linked.UserID != user.ID {
+			return nil, ErrIdentityAlreadyLinked
+		}
+		return linked, a.repo.UpdateExternalIdentity(ctx, linked, identityUpdate(identity))
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if _, err := a.repo.GetUserExternalIdentity(ctx, user.ID, identity.Provider); err == nil {
+		return nil, ErrIdentityAlreadyLinked
+	}
+	return a.createIdentity(ctx, user, identity)
+}
+
+// UnlinkIdentity foydalanuvchining boshqa kirish usuli qolmasa bog'lanishni o'chirmaydi.
+func (a *AuthUsecaseImpl) UnlinkIdentity(ctx context.Context, user *User, providerName string) error {
+	linked, err := a.repo.GetUserExternalIdentity(ctx, user.ID, providerName)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrIdentityNotLinked
+		}
+		return err
+	}
+	methods, err := a.LoginMethods(ctx, user)
+	if err != nil {
+		return err
+	}
+	if methods <= 1 {
+		return ErrLastLoginMethod
+	}
+	a.repo.DeleteExternalIdentity(ctx, linked)
+	a.logger.Info("external identity unlinked", zap.Uint("user_id", user.ID), zap.String("provider", providerName))
+	return nil
+}
+
+// LoginMethods foydalanuvchi hozir kira oladigan usullar soni: tasdiqlangan telefon va
+// parol, email orqali magic link, passkeylar va bog'langan identitylar.
+func (a *AuthUsecaseImpl) LoginMethods(ctx context.Context, user *User) (int64, error) {
+	identities, err := a.repo.GetExternalIdentities(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+	methods := int64(len(identities)) + a.repo.CountPasskeys(ctx, user.ID)
+	if user.Phone != nil && user.Password != "" && a.IsConfirm(ctx, user) {
+		methods++
+	}
+	if user.Email != nil && *user.Email != "" {
+		methods++
+	}
+	return methods, nil
+}
+
+func (a *AuthUsecaseImpl) GetIdentities(ctx context.Context, user *User) ([]ExternalIdentity, error) {
+	return a.repo.GetExternalIdentities(ctx, user.ID)
+}
+
+func (a *AuthUsecaseImpl) createIdentity(ctx context.Context, user *User, identity *social.Identity) (*ExternalIdentity, error) {
+	now := time.Now()
+	linked := &ExternalIdentity{
+		UserID:        user.ID,
+		Provider:      identity.Provider,
+		Subject:       identity.Subject,
+		EmailVerified: identity.EmailVerified,
+		LastLoginAt:   &now,
+	}
+	if identity.Email != "" {
+		email := identity.Email
+		linked.Email = &email
+	}
+	return a.repo.CreateExternalIdentity(ctx, linked)
+}
+
+func identityUpdate(identity *social.Identity) map[string]any {
+	update := map[string]any{
+		"email_verified": identity.EmailVerified,
+		"last_login_at":  time.Now(),
+	}
+	if identity.Email != "" {
+		update["email"] = identity.Email
+	}
+	return update
+}
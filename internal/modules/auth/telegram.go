@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/JscorpTech/auth/pkg/utils"
+	"gorm.io/gorm"
+)
+
+const ProviderTelegram = "telegram"
+
+// telegramClockSkew server soati Telegramnikidan biroz orqada bo'lishiga ruxsat beradi.
+const telegramClockSkew = time.Minute
+
+// TelegramAuth Login Widget imzosini va auth_date yangiligini tekshiradi, so'ng
+// foydalanuvchini Telegram ID ga bog'langan identity orqali topadi yoki yaratadi.
+func (a *AuthUsecaseImpl) TelegramAuth(ctx context.Context, payload *TelegramAuthRequest) (*User, error) {
+	if a.cfg.TelegramBotToken == "" {
+		return nil, ErrTelegramDisabled
+	}
+	if !utils.VerifyTelegramLogin(telegramCheckData(payload), payload.Hash, a.cfg.TelegramBotToken) {
+		return nil, ErrInvalidTelegramAuth
+	}
+	authDate := time.Unix(payload.AuthDate, 0)
+	now := time.Now()
+	if authDate.After(now.Add(telegramClockSkew)) || now.Sub(authDate) > time.Duration(a.cfg.TelegramAuthExp)*time.Second {
+		return nil, ErrTelegramAuthExpired
+	}
+
+	subject := strconv.FormatInt(payload.ID, 10)
+	linked, err := a.repo.GetExternalIdentity(ctx, ProviderTelegram, subject)
+	if err == nil {
+		user, err := a.linkedUser(ctx, linked)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.repo.UpdateExternalIdentity(ctx, linked, map[string]any{"last_login_at": now}); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	isSuperUser := false
+	isStaff := false
+	isActive := true
+	user, err := a.repo.Create(ctx, &User{
+		FirstName:   payload.FirstName,
+		LastName:    payload.LastName,
+		IsSuperuser: &isSuperUser,
+		IsStaff:     &isStaff,
+		IsActive:    &isActive,
+		DateJoined:  &now,
+		ValidatedAT: &now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = a.repo.CreateExternalIdentity(ctx, &ExternalIdentity{
+		UserID:      user.ID,
+		Provider:    ProviderTelegram,
+		Subject:     subject,
+		LastLoginAt: &now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// telegramCheckData widget yuborgan maydonlarni qaytaradi, bo'sh maydonlar imzoga kirmaydi.
+func telegramCheckData(payload *TelegramAuthRequest) map[string]string {
+	data := map[string]string{
+		"id":        strconv.FormatInt(payload.ID, 10),
+		"auth_date": strconv.FormatInt(payload.AuthDate, 10),
+	}
+	optional := map[string]string{
+		"first_name": payload.FirstName,
+		"last_name":  payload.LastName,
+		"username":   payload.Username,
+		"photo_url":  payload.PhotoURL,
+	}
+	for key, value := range optional {
+		if value != "" {
+			data[key] = value
+		}
+	}
+	return data
+}
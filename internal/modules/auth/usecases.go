@@ -6,10 +6,16 @@ import (
 	"time"
 
 	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/geoip"
+	"github.com/JscorpTech/auth/internal/modules/audit"
+	"github.com/JscorpTech/auth/internal/modules/smsguard"
+	"github.com/JscorpTech/auth/internal/password"
+	"github.com/JscorpTech/auth/internal/phone"
+	"github.com/JscorpTech/auth/internal/sms"
+	"github.com/JscorpTech/auth/internal/social"
 	"github.com/JscorpTech/auth/pkg/utils"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
-	"google.golang.org/api/idtoken"
 	"gorm.io/gorm"
 )
 
@@ -19,28 +25,103 @@ type AuthUsecase interface {
 	IsExists(context.Context, string) bool
 	GetUserByID(context.Context, int64) (*User, error)
 	ValidateToken(string) (*jwt.MapClaims, error)
-	AccessToken(*User) string
-	RefreshToken(*User) string
+	AccessToken(*User, *AuthContext) string
+	RefreshToken(*User, *AuthContext) string
 	SendOtp(context.Context, string) error
+	SetOtpChannel(context.Context, *User, OtpChannel) error
 	ValidateOtp(context.Context, string, string) bool
 	IsConfirm(context.Context, *User) bool
 	GetUserByPhone(context.Context, string) (*User, error)
 	Confirm(context.Context, *User)
-	ValidateGoogleIDToken(context.Context, string) (*idtoken.Payload, error)
-	GoogleAuth(context.Context, string) (*User, error)
+	SocialAuth(context.Context, string, string) (*User, error)
+	LinkIdentity(context.Context, *User, string, string) (*ExternalIdentity, error)
+	UnlinkIdentity(context.Context, *User, string) error
+	GetIdentities(context.Context, *User) ([]ExternalIdentity, error)
+	LoginMethods(context.Context, *User) (int64, error)
+	TelegramAuth(context.Context, *TelegramAuthRequest) (*User, error)
+	SeedPermissions(context.Context) error
+	RolePermissions(context.Context, Role) []string
+	ListPermissions(context.Context) ([]Permission, error)
+	CreatePermission(context.Context, string, string) (*Permission, error)
+	DeletePermission(context.Context, string) error
+	ListRoles(context.Context) (map[Role][]string, error)
+	SetRolePermissions(context.Context, Role, []string) ([]string, error)
+	CurrentUser(context.Context, jwt.MapClaims) (*User, error)
+	CheckStatus(*User) error
+	Refresh(context.Context, string) (*User, *AuthContext, error)
+	Suspend(context.Context, *User, *User, string, time.Time) error
+	Unsuspend(context.Context, *User, *User) error
+	ImpersonationToken(context.Context, *User, *User) (string, time.Time, error)
+	ListUsers(context.Context, UserFilter) ([]User, int64, error)
+	ChangeRole(context.Context, *User, *User, Role) error
+	SetActive(context.Context, *User, *User, bool) error
+	ForcePasswordReset(context.Context, *User, *User) error
+	Unlock(context.Context, *User, *User) error
+	RevokeSessions(context.Context, *User)
+	RequestPasswordReset(context.Context, string) error
+	ResetPassword(context.Context, string, string, string) error
+	ChangePassword(context.Context, *User, string, string) error
+	ValidatePassword(string, *User) error
+	HashPassword(string) (string, error)
+	MfaEnabled(context.Context, *User) bool
+	MfaMethods(context.Context, *User) []string
+	MfaToken(*User, *AuthContext) string
+	CompleteLogin(context.Context, *User, *AuthContext) (*AuthLoginResponse, *AuthMfaChallengeResponse)
+	ValidateMfaToken(context.Context, string) (*User, *AuthContext, error)
+	EnrollTotp(context.Context, *User) (string, string, error)
+	ReenrollTotp(context.Context, *User, string) (string, string, error)
+	ConfirmTotp(context.Context, *User, string) ([]string, error)
+	DisableTotp(context.Context, *User, string) error
+	VerifyTotp(context.Context, *User, string) error
+	VerifySecondFactor(context.Context, *User, string) error
+	VerifyRecoveryCode(context.Context, *User, string) error
+	RecoveryCodesRemaining(context.Context, *User) int64
+	RegenerateRecoveryCodes(context.Context, *User, string, string) ([]string, error)
+	SendMagicLink(context.Context, string, string) error
+	VerifyMagicLink(context.Context, string, string) (*User, error)
+	Reauthenticate(context.Context, *User, string, string) (*AuthContext, error)
+	LoginSucceeded(context.Context, *User, *AuthContext)
+	LoginFailed(context.Context, *User, string, error)
+	ListDevices(context.Context, *User) ([]Device, error)
+	RevokeDevice(context.Context, *User, uint) error
+	RevokeDeviceByToken(context.Context, string) error
 }
 
 type AuthUsecaseImpl struct {
-	repo   AuthRepository
+	repo  AuthRepository
+	sms   sms.SmsProvider
+	email sms.SmsProvider
+	// telegram bot sozlanmagan bo'lsa nil
+	telegram    sms.SmsProvider
+	providers   map[string]social.IdentityProvider
+	permissions *permissionCache
+	audit       audit.AuditUsecase
+	smsGuard    smsguard.SmsGuardUsecase
+	phones      phone.Parser
+	// GEOIP_DB_PATH berilmagan bo'lsa nil
+	geo    geoip.CountryResolver
+	policy *password.Policy
+	hasher password.PasswordHasher
 	cfg    *config.Config
 	logger *zap.Logger
 }
 
-func NewAuthUsecase(repo AuthRepository, cfg *config.Config, logger *zap.Logger) AuthUsecase {
+func NewAuthUsecase(repo AuthRepository, smsProvider sms.SmsProvider, email sms.SmsProvider, telegram sms.SmsProvider, providers map[string]social.IdentityProvider, auditUsecase audit.AuditUsecase, smsGuard smsguard.SmsGuardUsecase, phones phone.Parser, geo geoip.CountryResolver, policy *password.Policy, hasher password.PasswordHasher, cfg *config.Config, logger *zap.Logger) AuthUsecase {
 	return &AuthUsecaseImpl{
-		repo:   repo,
-		cfg:    cfg,
-		logger: logger,
+		repo:        repo,
+		sms:         smsProvider,
+		email:       email,
+		telegram:    telegram,
+		providers:   providers,
+		permissions: &permissionCache{},
+		audit:       auditUsecase,
+		smsGuard:    smsGuard,
+		phones:      phones,
+		geo:         geo,
+		policy:      policy,
+		hasher:      hasher,
+		cfg:         cfg,
+		logger:      logger,
 	}
 }
 
@@ -48,53 +129,6 @@ func (a *AuthUsecaseImpl) GetUserByID(ctx context.Context, id int64) (*User, err
 	return a.repo.GetID(ctx, id)
 }
 
-func (a *AuthUsecaseImpl) GoogleAuth(ctx context.Context, idToken string) (*User, error) {
-	payload, err := a.ValidateGoogleIDToken(ctx, idToken)
-	if err != nil {
-		return nil, err
-	}
-	email, ok := payload.Claims["email"].(string)
-	if !ok {
-		return nil, errors.New("email claim not found in ID token")
-	}
-	userInstance, err := a.repo.GetByEmail(ctx, email)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			firstName, _ := payload.Claims["given_name"].(string)
-			lastName, _ := payload.Claims["family_name"].(string)
-			now := time.Now()
-			isSuperUser := false
-			isStaff := false
-			isActive := true
-			dateJoined := time.Now()
-			user := &User{
-				Email:       &email,
-				FirstName:   firstName,
-				LastName:    lastName,
-				IsSuperuser: &isSuperUser,
-				IsStaff:     &isStaff,
-				IsActive:    &isActive,
-				DateJoined:  &dateJoined,
-				ValidatedAT: &now,
-			}
-			if userInstance, err = a.repo.Create(ctx, user); err != nil {
-				return nil, err
-			}
-		} else {
-			return nil, err
-		}
-	}
-	return userInstance, nil
-}
-
-func (a *AuthUsecaseImpl) ValidateGoogleIDToken(ctx context.Context, idToken string) (*idtoken.Payload, error) {
-	claims, err := idtoken.Validate(ctx, idToken, a.cfg.GoogleClientID)
-	if err != nil {
-		return nil, err
-	}
-	return claims, nil
-}
-
 func (a *AuthUsecaseImpl) IsConfirm(ctx context.Context, user *User) bool {
 	return user.ValidatedAT != nil
 }
@@ -104,36 +138,84 @@ func (a *AuthUsecaseImpl) ValidateToken(token string) (*jwt.MapClaims, error) {
 	if err != nil {
 		return nil, err
 	}
-	if claims["type"] != "refresh" {
+	if claims["token_type"] != "refresh" {
 		return nil, ErrInvalidRefreshToken
 	}
 	return &claims, nil
 }
 
+// Refresh foydalanuvchini bazadan qayta yuklaydi, shunda rol o'zgarishi, bloklash va
+// sessiyalarni bekor qilish refresh token orqali chetlab o'tilmaydi.
+func (a *AuthUsecaseImpl) Refresh(ctx context.Context, token string) (*User, *AuthContext, error) {
+	claims, err := a.ValidateToken(token)
+	if err != nil {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	user, err := a.CurrentUser(ctx, *claims)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrSessionRevoked) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, err
+	}
+	return user, AuthContextFromClaims(*claims), nil
+}
+
 func (a *AuthUsecaseImpl) Login(ctx context.Context, phone string, password string) (*User, error) {
+	phone, err := a.phones.Normalize(phone)
+	if err != nil {
+		return nil, err
+	}
 	user, err := a.repo.GetByPhone(ctx, phone)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			a.record(ctx, audit.TypeLoginFailure, nil, map[string]any{
+				"method": AmrPassword,
+				"phone":  phone,
+				"reason": ErrInvalidCredentions.Error(),
+			})
 			return nil, ErrInvalidCredentions
 		}
 		return nil, err
 	}
-	if !a.IsConfirm(ctx, user) {
-		return nil, ErrPhoneNumberNotConfirmed
+	if err := a.checkLogin(ctx, user, password); err != nil {
+		a.LoginFailed(ctx, user, AmrPassword, err)
+		return nil, err
 	}
+	return user, nil
+}
 
-	if res := utils.CheckPasswordHash(password, user.Password); !res {
-		return nil, ErrInvalidPassword
+func (a *AuthUsecaseImpl) checkLogin(ctx context.Context, user *User, password string) error {
+	if !a.IsConfirm(ctx, user) {
+		return ErrPhoneNumberNotConfirmed
 	}
-	return user, nil
+	if err := a.checkPassword(ctx, user, password); err != nil {
+		return err
+	}
+	if err := a.CheckStatus(user); err != nil {
+		return err
+	}
+	if user.PasswordResetRequired {
+		return ErrPasswordResetRequired
+	}
+	return nil
 }
 
 func (a *AuthUsecaseImpl) IsExists(ctx context.Context, phone string) bool {
+	phone, err := a.phones.Normalize(phone)
+	if err != nil {
+		return false
+	}
 	return a.repo.IsExists(ctx, phone)
 }
 
 func (a *AuthUsecaseImpl) Register(ctx context.Context, user *User) (*User, error) {
-	userInstance, err := a.repo.GetByPhone(ctx, *user.Phone)
+	phone, err := a.phones.Normalize(*user.Phone)
+	if err != nil {
+		return nil, err
+	}
+	user.Phone = &phone
+	userInstance, err := a.repo.GetByPhone(ctx, phone)
 	if err == nil && a.IsConfirm(ctx, userInstance) {
 		return nil, ErrUserAlreadyExists
 	}
@@ -146,17 +228,22 @@ func (a *AuthUsecaseImpl) Register(ctx context.Context, user *User) (*User, erro
 	if err != nil {
 		return nil, err
 	}
+	a.record(ctx, audit.TypeRegister, userInstance, nil)
 	return userInstance, nil
 }
 
-func (a *AuthUsecaseImpl) AccessToken(user *User) string {
+func (a *AuthUsecaseImpl) AccessToken(user *User, authCtx *AuthContext) string {
 	claims := jwt.MapClaims{
 		"user_id":    user.ID,
 		"exp":        time.Now().Add(time.Minute * time.Duration(a.cfg.AccessExp)).Unix(),
 		"token_type": "access",
 		"jti":        utils.RandomString(20, "1234567890"),
+		"iat":        time.Now().Unix(),
 		"role":       user.Role,
+		// permissions boshqa servislar avtorizatsiyani auth ga murojaat qilmasdan tekshirishi uchun
+		"permissions": a.RolePermissions(context.Background(), user.Role),
 	}
+	authCtx.apply(claims)
 	token, err := utils.CreateJWT(claims, a.cfg.PrivateKey)
 	if err != nil {
 		a.logger.Error("create access token error", zap.Error(err))
@@ -165,14 +252,16 @@ func (a *AuthUsecaseImpl) AccessToken(user *User) string {
 	return token
 }
 
-func (a *AuthUsecaseImpl) RefreshToken(user *User) string {
+func (a *AuthUsecaseImpl) RefreshToken(user *User, authCtx *AuthContext) string {
 	claims := jwt.MapClaims{
 		"user_id":    user.ID,
 		"exp":        time.Now().Add(time.Minute * time.Duration(a.cfg.RefreshExp)).Unix(),
 		"token_type": "refresh",
 		"jti":        utils.RandomString(20, "1234567890"),
+		"iat":        time.Now().Unix(),
 		"role":       user.Role,
 	}
+	authCtx.apply(claims)
 	token, err := utils.CreateJWT(claims, a.cfg.PrivateKey)
 	if err != nil {
 		a.logger.Error("create refresh token error", zap.Error(err))
@@ -181,7 +270,49 @@ func (a *AuthUsecaseImpl) RefreshToken(user *User) string {
 	return token
 }
 
-func (a *AuthUsecaseImpl) SendOtp(ctx context.Context, phone string) error {
+// Reauthenticate step-up uchun: parol va/yoki TOTP kodni tekshirib yangi AuthContext
+// qaytaradi. 2FA yoqilgan bo'lsa va ikkala faktor berilsa acr mfa bo'ladi.
+func (a *AuthUsecaseImpl) Reauthenticate(ctx context.Context, user *User, password string, code string) (*AuthContext, error) {
+	if password == "" && code == "" {
+		return nil, ErrInvalidCredentions
+	}
+	var authCtx *AuthContext
+	if password != "" {
+		if err := a.checkPassword(ctx, user, password); err != nil {
+			return nil, err
+		}
+		authCtx = NewAuthContext(AcrBasic, AmrPassword)
+	}
+	if code != "" {
+		if err := a.VerifySecondFactor(ctx, user, code); err != nil {
+			return nil, err
+		}
+		if authCtx == nil {
+			authCtx = NewAuthContext(AcrBasic, AmrOtp)
+		} else {
+			authCtx = authCtx.WithSecondFactor(AmrOtp)
+		}
+	}
+	return authCtx, nil
+}
+
+func (a *AuthUsecaseImpl) SendOtp(ctx context.Context, phone string) (err error) {
+	phone, err = a.phones.Normalize(phone)
+	if err != nil {
+		return err
+	}
+	user, _ := a.repo.GetByPhone(ctx, phone)
+	channel := a.otpChannel(user)
+	send, err := a.smsGuard.Reserve(ctx, phone, string(channel))
+	if err != nil {
+		return err
+	}
+	// kod yuborilmasa band qilingan joy bo'shatiladi
+	defer func() {
+		if err != nil && send != nil {
+			a.smsGuard.Release(ctx, send)
+		}
+	}()
 	code := utils.RandomOtp(6)
 	a.logger.Info("New otp", zap.String("otp", code))
 	otp, err := a.repo.GetOtpByPhone(ctx, phone)
@@ -196,20 +327,67 @@ func (a *AuthUsecaseImpl) SendOtp(ctx context.Context, phone string) error {
 	if err := a.repo.UpdateOtp(ctx, phone, code); err != nil {
 		return err
 	}
+	msg := "Tasdiqlash kodi: " + code
+	if channel == OtpChannelTelegram {
+		if err = a.telegram.Send(phone, msg); err == nil {
+			a.record(ctx, audit.TypeOtpSent, user, map[string]any{"phone": phone, "channel": channel})
+			return nil
+		}
+		a.logger.Info("telegram otp failed, falling back to sms", zap.Uint("user_id", user.ID), zap.Error(err))
+		// SMS ga o'tilsa yuborish SMS limitlari bilan qaytadan band qilinadi
+		a.smsGuard.Release(ctx, send)
+		channel = OtpChannelSms
+		if send, err = a.smsGuard.Reserve(ctx, phone, string(channel)); err != nil {
+			return err
+		}
+	}
+	if err = a.sms.Send(phone, msg); err != nil {
+		return err
+	}
+	a.smsGuard.Record(ctx, send)
+	a.record(ctx, audit.TypeOtpSent, user, map[string]any{"phone": phone, "channel": channel})
+	return nil
+}
+
+// otpChannel foydalanuvchi Telegramni tanlagan va bot sozlangan bo'lsa Telegram, aks holda
+// SMS. user ro'yxatdan o'tmagan raqam uchun nil.
+func (a *AuthUsecaseImpl) otpChannel(user *User) OtpChannel {
+	if a.telegram != nil && user != nil && user.OtpChannel == OtpChannelTelegram {
+		return OtpChannelTelegram
+	}
+	return OtpChannelSms
+}
+
+func (a *AuthUsecaseImpl) SetOtpChannel(ctx context.Context, user *User, channel OtpChannel) error {
+	if channel == OtpChannelTelegram && a.telegram == nil {
+		return ErrOtpChannelUnavailable
+	}
+	a.repo.Update(ctx, user, map[string]any{"otp_channel": channel})
+	user.OtpChannel = channel
 	return nil
 }
 
 func (a *AuthUsecaseImpl) ValidateOtp(ctx context.Context, phone string, otp string) bool {
+	phone, err := a.phones.Normalize(phone)
+	if err != nil {
+		return false
+	}
 	otpInstance, err := a.repo.GetOtp(ctx, phone, otp)
 	if err != nil {
 		a.logger.Info("invalid otp", zap.Error(err))
 		return false
 	}
 	a.repo.DeleteOtp(ctx, otpInstance)
+	user, _ := a.repo.GetByPhone(ctx, phone)
+	a.record(ctx, audit.TypeOtpVerified, user, map[string]any{"phone": phone})
 	return true
 }
 
 func (a *AuthUsecaseImpl) GetUserByPhone(ctx context.Context, phone string) (*User, error) {
+	phone, err := a.phones.Normalize(phone)
+	if err != nil {
+		return nil, err
+	}
 	return a.repo.GetByPhone(ctx, phone)
 }
 
@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client Telegram Bot API uchun minimal HTTP client. baseURL testlarda soxta
+// serverga yo'naltirish uchun sozlanadi.
+type Client struct {
+	token   string
+	baseURL string
+	http    *http.Client
+}
+
+func NewClient(token string, baseURL string) *Client {
+	return &Client{
+		token:   token,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// APIError Bot API ok=false qaytargandagi xato, 403 bot bloklanganini bildiradi.
+type APIError struct {
+	Code        int
+	Description string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram api error %d: %s", e.Code, e.Description)
+}
+
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string, replyMarkup any) error {
+	body := map[string]any{
+		"chat_id": chatID,
+		"text":    text,
+	}
+	if replyMarkup != nil {
+		body["reply_markup"] = replyMarkup
+	}
+	return c.call(ctx, "sendMessage", body)
+}
+
+func (c *Client) call(ctx context.Context, method string, body any) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/bot"+c.token+"/"+method, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// url.Error ichidagi manzilda bot token bor, u logga tushmasligi kerak.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return fmt.Errorf("telegram %s: %w", method, urlErr.Err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	var result apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram %s: status %d: %w", method, resp.StatusCode, err)
+	}
+	if !result.Ok {
+		return &APIError{Code: result.ErrorCode, Description: result.Description}
+	}
+	return nil
+}
+
+func contactKeyboard() map[string]any {
+	return map[string]any{
+		"keyboard": [][]map[string]any{
+			{{"text": "📱 Telefon raqamni yuborish", "request_contact": true}},
+		},
+		"resize_keyboard":   true,
+		"one_time_keyboard": true,
+	}
+}
+
+func removeKeyboard() map[string]any {
+	return map[string]any{"remove_keyboard": true}
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/dto"
+	"github.com/JscorpTech/auth/internal/modules/bot"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type BotHandler struct {
+	usecase bot.BotUsecase
+	cfg     *config.Config
+	logger  *zap.Logger
+}
+
+func NewBotHandler(usecase bot.BotUsecase, cfg *config.Config, logger *zap.Logger) *BotHandler {
+	return &BotHandler{
+		usecase: usecase,
+		cfg:     cfg,
+		logger:  logger,
+	}
+}
+
+// @Router /api/v1/auth/bot/webhook [post]
+// @Summary Telegram bot webhook
+// @Description Called by Telegram. Requests must carry X-Telegram-Bot-Api-Secret-Token.
+// @Tags bot
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.BaseResponse
+func (h *BotHandler) Webhook(c *gin.Context) {
+	secret := c.GetHeader("X-Telegram-Bot-Api-Secret-Token")
+	if h.cfg.TelegramWebhookSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(h.cfg.TelegramWebhookSecret)) != 1 {
+		dto.JSON(c, http.StatusUnauthorized, nil, "Invalid secret token")
+		return
+	}
+	var update bot.Update
+	if err := c.ShouldBindJSON(&update); err != nil {
+		dto.JSON(c, http.StatusBadRequest, nil, "Invalid request")
+		return
+	}
+	// Telegram 200 bo'lmagan javobda update ni qayta yuboraveradi, shuning uchun
+	// ishlov berishdagi xato faqat logga yoziladi.
+	if err := h.usecase.HandleUpdate(c.Request.Context(), &update); err != nil {
+		h.logger.Error("telegram update error", zap.Int64("update_id", update.UpdateID), zap.Error(err))
+	}
+	dto.JSON(c, http.StatusOK, nil, "")
+}
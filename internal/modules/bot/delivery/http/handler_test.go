@@ -0,0 +1,163 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/modules/bot"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	testToken  = "123:secret-token"
+	testSecret = "webhook-secret"
+)
+
+// fakeBotAPI Bot API o'rnida sendMessage chaqiruvlarini yozib boradi. blocked dagi
+// chatlarga Telegram kabi 403 qaytaradi.
+type fakeBotAPI struct {
+	mu       sync.Mutex
+	messages []map[string]any
+	blocked  map[int64]bool
+}
+
+func (f *fakeBotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.URL.Path != "/bot"+testToken+"/sendMessage" {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": 404, "description": "Not Found"})
+		return
+	}
+	var body map[string]any
+	json.NewDecoder(r.Body).Decode(&body)
+	if f.blocked[int64(body["chat_id"].(float64))] {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": 403, "description": "Forbidden: bot was blocked by the user"})
+		return
+	}
+	f.messages = append(f.messages, body)
+	json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": map[string]any{}})
+}
+
+func (f *fakeBotAPI) block(chatID int64, blocked bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blocked[chatID] = blocked
+}
+
+func (f *fakeBotAPI) last(t *testing.T) map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.messages) == 0 {
+		t.Fatal("no messages sent")
+	}
+	return f.messages[len(f.messages)-1]
+}
+
+func setup(t *testing.T) (*gin.Engine, bot.BotUsecase, *fakeBotAPI) {
+	gin.SetMode(gin.TestMode)
+	api := &fakeBotAPI{blocked: map[int64]bool{}}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&bot.Chat{}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		TelegramBotToken:      testToken,
+		TelegramAPIURL:        server.URL,
+		TelegramWebhookSecret: testSecret,
+	}
+	usecase := bot.NewBotUsecase(bot.NewBotRepository(db), bot.NewClient(cfg.TelegramBotToken, cfg.TelegramAPIURL), zap.NewNop())
+	router := gin.New()
+	RegisterBotRoutes(router.Group(""), NewBotHandler(usecase, cfg, zap.NewNop()))
+	return router, usecase, api
+}
+
+func webhook(t *testing.T, router *gin.Engine, secret string, update string) int {
+	req := httptest.NewRequest(http.MethodPost, "/bot/webhook", bytes.NewBufferString(update))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Telegram-Bot-Api-Secret-Token", secret)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func contactUpdate(chatID int64, fromID int64, contactUserID int64, phone string) string {
+	return `{"update_id":1,"message":{"message_id":1,"from":{"id":` + itoa(fromID) + `},` +
+		`"chat":{"id":` + itoa(chatID) + `,"type":"private"},` +
+		`"contact":{"phone_number":"` + phone + `","user_id":` + itoa(contactUserID) + `}}}`
+}
+
+func itoa(n int64) string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
+
+func TestWebhookRejectsInvalidSecret(t *testing.T) {
+	router, _, _ := setup(t)
+	if code := webhook(t, router, "wrong", contactUpdate(10, 10, 10, "+998901112233")); code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
+
+func TestContactFlowAndOtpDelivery(t *testing.T) {
+	router, usecase, api := setup(t)
+	sender := usecase.Sender()
+
+	start := `{"update_id":1,"message":{"message_id":1,"from":{"id":10},"chat":{"id":10,"type":"private"},"text":"/start"}}`
+	if code := webhook(t, router, testSecret, start); code != http.StatusOK {
+		t.Fatalf("start status = %d", code)
+	}
+	if _, ok := api.last(t)["reply_markup"].(map[string]any)["keyboard"]; !ok {
+		t.Fatal("start reply has no contact keyboard")
+	}
+
+	// boshqa odamning kontakti bog'lanmaydi
+	webhook(t, router, testSecret, contactUpdate(10, 10, 99, "+998901112233"))
+	if err := sender.Send("+998901112233", "Tasdiqlash kodi: 111111"); !errors.Is(err, bot.ErrChatNotLinked) {
+		t.Fatalf("foreign contact: err = %v, want %v", err, bot.ErrChatNotLinked)
+	}
+
+	if code := webhook(t, router, testSecret, contactUpdate(10, 10, 10, "998901112233")); code != http.StatusOK {
+		t.Fatalf("contact status = %d", code)
+	}
+	if err := sender.Send("+998901112233", "Tasdiqlash kodi: 123456"); err != nil {
+		t.Fatal(err)
+	}
+	message := api.last(t)
+	if message["chat_id"].(float64) != 10 || !strings.Contains(message["text"].(string), "123456") {
+		t.Fatalf("unexpected otp message: %v", message)
+	}
+}
+
+func TestBlockedChatFallsBack(t *testing.T) {
+	router, usecase, api := setup(t)
+	sender := usecase.Sender()
+	webhook(t, router, testSecret, contactUpdate(20, 20, 20, "+998901112244"))
+
+	api.block(20, true)
+	if err := sender.Send("+998901112244", "Tasdiqlash kodi: 123456"); !errors.Is(err, bot.ErrChatNotLinked) {
+		t.Fatalf("err = %v, want %v", err, bot.ErrChatNotLinked)
+	}
+	// chat bloklangan deb belgilangan, Bot API ga qayta murojaat qilinmaydi
+	api.block(20, false)
+	if err := sender.Send("+998901112244", "Tasdiqlash kodi: 654321"); !errors.Is(err, bot.ErrChatNotLinked) {
+		t.Fatalf("err = %v, want %v", err, bot.ErrChatNotLinked)
+	}
+}
@@ -0,0 +1,12 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterBotRoutes(router *gin.RouterGroup, h *BotHandler) {
+	public := router.Group("/bot")
+	{
+		public.POST("/webhook", h.Webhook)
+	}
+}
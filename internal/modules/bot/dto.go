@@ -0,0 +1,37 @@
+package bot
+
+// Bot API obyektlaridan faqat kerakli maydonlar.
+// https://core.telegram.org/bots/api#update
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+type Message struct {
+	MessageID int64    `json:"message_id"`
+	From      *From    `json:"from"`
+	Chat      ChatInfo `json:"chat"`
+	Text      string   `json:"text"`
+	Contact   *Contact `json:"contact"`
+}
+
+type From struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type ChatInfo struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+type Contact struct {
+	PhoneNumber string `json:"phone_number"`
+	UserID      int64  `json:"user_id"`
+}
+
+type apiResponse struct {
+	Ok          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
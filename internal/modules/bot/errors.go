@@ -0,0 +1,9 @@
+package bot
+
+import "errors"
+
+var (
+	ErrChatNotLinked    = errors.New("phone is not linked to telegram")
+	ErrForeignContact   = errors.New("contact does not belong to the sender")
+	ErrBotNotConfigured = errors.New("telegram bot is not configured")
+)
@@ -0,0 +1,21 @@
+package bot
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Chat bot bilan kontaktini ulashgan foydalanuvchining telefon raqamini chat ID ga bog'laydi.
+type Chat struct {
+	gorm.Model
+	ChatID     int64      `gorm:"column:chat_id;uniqueIndex"`
+	TelegramID int64      `gorm:"column:telegram_id;index"`
+	Phone      string     `gorm:"column:phone;uniqueIndex"`
+	Username   string     `gorm:"column:username"`
+	BlockedAt  *time.Time `gorm:"column:blocked_at"`
+}
+
+func (*Chat) TableName() string {
+	return "telegram_chat"
+}
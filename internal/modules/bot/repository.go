@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BotRepository interface {
+	GetByPhone(context.Context, string) (*Chat, error)
+	SaveChat(context.Context, *Chat) error
+	UpdateChat(context.Context, *Chat, map[string]any) error
+}
+
+type BotRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewBotRepository(db *gorm.DB) BotRepository {
+	return &BotRepositoryImpl{
+		db: db,
+	}
+}
+
+func (b *BotRepositoryImpl) GetByPhone(ctx context.Context, phone string) (*Chat, error) {
+	var chat Chat
+	if err := b.db.WithContext(ctx).Where("phone = ?", phone).First(&chat).Error; err != nil {
+		return nil, err
+	}
+	return &chat, nil
+}
+
+// SaveChat telefon bo'yicha mavjud yozuvni yangilaydi, shu raqam boshqa chatga o'tsa
+// eski bog'lanish ham, eski chatdagi boshqa raqam ham o'chiriladi.
+func (b *BotRepositoryImpl) SaveChat(ctx context.Context, chat *Chat) error {
+	return b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("chat_id = ? and phone <> ?", chat.ChatID, chat.Phone).Delete(&Chat{}).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "phone"}},
+			DoUpdates: clause.AssignmentColumns([]string{"chat_id", "telegram_id", "username", "blocked_at", "updated_at"}),
+		}).Create(chat).Error
+	})
+}
+
+func (b *BotRepositoryImpl) UpdateChat(ctx context.Context, chat *Chat, update map[string]any) error {
+	return b.db.WithContext(ctx).Model(chat).Updates(update).Error
+}
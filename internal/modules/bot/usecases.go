@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/sms"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type BotUsecase interface {
+	HandleUpdate(context.Context, *Update) error
+	// Sender telefon raqamiga bot orqali xabar yuboradigan provider, OTP kanali sifatida ishlatiladi.
+	Sender() sms.SmsProvider
+}
+
+type BotUsecaseImpl struct {
+	repo   BotRepository
+	client *Client
+	logger *zap.Logger
+}
+
+func NewBotUsecase(repo BotRepository, client *Client, logger *zap.Logger) BotUsecase {
+	return &BotUsecaseImpl{
+		repo:   repo,
+		client: client,
+		logger: logger,
+	}
+}
+
+func (b *BotUsecaseImpl) HandleUpdate(ctx context.Context, update *Update) error {
+	message := update.Message
+	if message == nil || message.From == nil || message.Chat.Type != "private" {
+		return nil
+	}
+	if message.Contact != nil {
+		return b.linkContact(ctx, message)
+	}
+	if strings.HasPrefix(message.Text, "/start") {
+		return b.client.SendMessage(ctx, message.Chat.ID,
+			"Tasdiqlash kodlarini shu yerda olish uchun telefon raqamingizni yuboring.", contactKeyboard())
+	}
+	return nil
+}
+
+// linkContact faqat foydalanuvchining o'z kontaktini qabul qiladi, aks holda istalgan
+// raqamni o'z chatiga bog'lab boshqalarning kodlarini olish mumkin bo'lardi.
+func (b *BotUsecaseImpl) linkContact(ctx context.Context, message *Message) error {
+	if message.Contact.UserID != message.From.ID {
+		b.logger.Info("foreign contact shared", zap.Int64("chat_id", message.Chat.ID))
+		return b.client.SendMessage(ctx, message.Chat.ID, "Iltimos, o'zingizning raqamingizni yuboring.", contactKeyboard())
+	}
+	phone := utils.PhoneDigits(message.Contact.PhoneNumber)
+	err := b.repo.SaveChat(ctx, &Chat{
+		ChatID:     message.Chat.ID,
+		TelegramID: message.From.ID,
+		Phone:      phone,
+		Username:   message.From.Username,
+	})
+	if err != nil {
+		return err
+	}
+	b.logger.Info("telegram chat linked", zap.Int64("chat_id", message.Chat.ID))
+	return b.client.SendMessage(ctx, message.Chat.ID, "Raqamingiz bog'landi. Tasdiqlash kodlari endi shu yerga keladi.", removeKeyboard())
+}
+
+// send raqamga bog'langan chatga xabar yuboradi. Foydalanuvchi botni bloklagan bo'lsa
+// chat belgilab qo'yiladi va keyingi safar darhol ErrChatNotLinked qaytadi.
+func (b *BotUsecaseImpl) send(ctx context.Context, phone string, msg string) error {
+	chat, err := b.repo.GetByPhone(ctx, utils.PhoneDigits(phone))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrChatNotLinked
+		}
+		return err
+	}
+	if chat.BlockedAt != nil {
+		return ErrChatNotLinked
+	}
+	err = b.client.SendMessage(ctx, chat.ChatID, msg, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden {
+		if updateErr := b.repo.UpdateChat(ctx, chat, map[string]any{"blocked_at": time.Now()}); updateErr != nil {
+			b.logger.Error("telegram chat update error", zap.Error(updateErr))
+		}
+		return ErrChatNotLinked
+	}
+	return err
+}
+
+func (b *BotUsecaseImpl) Sender() sms.SmsProvider {
+	return &sender{usecase: b}
+}
+
+type sender struct {
+	usecase *BotUsecaseImpl
+}
+
+func (s *sender) Send(phone string, msg string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	return s.usecase.send(ctx, phone, msg)
+}
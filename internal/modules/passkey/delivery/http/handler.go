@@ -0,0 +1,214 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/JscorpTech/auth/internal/dto"
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/JscorpTech/auth/internal/modules/passkey"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+type PasskeyHandler struct {
+	usecase passkey.PasskeyUsecase
+	auth    auth.AuthUsecase
+	logger  *zap.Logger
+}
+
+func NewPasskeyHandler(usecase passkey.PasskeyUsecase, authUsecase auth.AuthUsecase, logger *zap.Logger) *PasskeyHandler {
+	return &PasskeyHandler{
+		usecase: usecase,
+		auth:    authUsecase,
+		logger:  logger,
+	}
+}
+
+func (h *PasskeyHandler) currentUser(c *gin.Context) (*auth.User, bool) {
+	user, err := h.auth.CurrentUser(c.Request.Context(), c.MustGet("user").(jwt.MapClaims))
+	if err != nil {
+		if status, ok := auth.AccountStatus(err); ok {
+			dto.JSON(c, http.StatusForbidden, status, err.Error())
+			return nil, false
+		}
+		dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+		return nil, false
+	}
+	return user, true
+}
+
+// @Router /api/v1/auth/passkey/register/begin [post]
+// @Summary Start passkey registration
+// @Description Returns PublicKeyCredentialCreationOptions for navigator.credentials.create()
+// @Tags passkey
+// @Produce json
+// @Success 200 {object} dto.BaseResponse
+func (h *PasskeyHandler) BeginRegistration(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	creation, err := h.usecase.BeginRegistration(c.Request.Context(), user)
+	if err != nil {
+		h.logger.Error("passkey registration begin error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		return
+	}
+	dto.JSON(c, http.StatusOK, creation, "")
+}
+
+// @Router /api/v1/auth/passkey/register/finish [post]
+// @Summary Finish passkey registration
+// @Description Body is the PublicKeyCredential returned by navigator.credentials.create()
+// @Tags passkey
+// @Accept json
+// @Produce json
+// @Param name query string false "Passkey name"
+// @Success 200 {object} dto.BaseResponse{data=passkey.CredentialDTO}
+func (h *PasskeyHandler) FinishRegistration(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	credential, err := h.usecase.FinishRegistration(c.Request.Context(), user, c.Query("name"), c.Request.Body)
+	if err != nil {
+		h.passkeyError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, passkey.ToCredential(credential), "")
+}
+
+// @Router /api/v1/auth/passkey/login/begin [post]
+// @Summary Start passkey login
+// @Description Returns PublicKeyCredentialRequestOptions for navigator.credentials.get(). Without phone a discoverable login is started.
+// @Tags passkey
+// @Accept json
+// @Produce json
+// @Param request body passkey.LoginBeginRequest false "Login begin request"
+// @Success 200 {object} dto.BaseResponse
+func (h *PasskeyHandler) BeginLogin(c *gin.Context) {
+	var payload passkey.LoginBeginRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			dto.JSON(c, http.StatusBadRequest, nil, "Invalid request")
+			return
+		}
+	}
+	assertion, err := h.usecase.BeginLogin(c.Request.Context(), payload.Phone)
+	if err != nil {
+		h.logger.Error("passkey login begin error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		return
+	}
+	dto.JSON(c, http.StatusOK, assertion, "")
+}
+
+// @Router /api/v1/auth/passkey/login/finish [post]
+// @Summary Finish passkey login
+// @Description Body is the PublicKeyCredential returned by navigator.credentials.get(). Without user verification the passkey is a single factor and accounts with 2FA get an mfa_token.
+// @Tags passkey
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=auth.AuthLoginResponse}
+// @Success 202 {object} dto.BaseResponse{data=auth.AuthMfaChallengeResponse}
+func (h *PasskeyHandler) FinishLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+	user, authCtx, err := h.usecase.FinishLogin(ctx, c.Request.Body)
+	if err != nil {
+		if errors.Is(err, passkey.ErrInvalidChallenge) || errors.Is(err, passkey.ErrInvalidCredential) || errors.Is(err, passkey.ErrCloneDetected) {
+			h.auth.LoginFailed(ctx, nil, auth.AmrHardwareKey, err)
+		}
+		h.passkeyError(c, err)
+		return
+	}
+	if err := h.auth.CheckStatus(user); err != nil {
+		h.auth.LoginFailed(ctx, user, auth.AmrHardwareKey, err)
+		h.passkeyError(c, err)
+		return
+	}
+	// UV siz passkey bitta faktor, 2FA yoqilgan bo'lsa /login/mfa talab qilinadi
+	tokens, challenge := h.auth.CompleteLogin(ctx, user, authCtx)
+	if challenge != nil {
+		dto.JSON(c, http.StatusAccepted, challenge, "")
+		return
+	}
+	dto.JSON(c, http.StatusOK, tokens, "")
+}
+
+// @Router /api/v1/auth/passkey/credentials [get]
+// @Summary List passkeys
+// @Tags passkey
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=[]passkey.CredentialDTO}
+func (h *PasskeyHandler) ListCredentials(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	credentials, err := h.usecase.ListCredentials(c.Request.Context(), user)
+	if err != nil {
+		h.passkeyError(c, err)
+		return
+	}
+	items := make([]passkey.CredentialDTO, 0, len(credentials))
+	for _, credential := range credentials {
+		items = append(items, passkey.ToCredential(&credential))
+	}
+	dto.JSON(c, http.StatusOK, items, "")
+}
+
+// @Router /api/v1/auth/passkey/credentials/{id} [delete]
+// @Summary Delete passkey
+// @Description Requires a recent authentication. The only remaining login method can not be deleted.
+// @Tags passkey
+// @Produce json
+// @Param id path int true "Passkey ID"
+// @Success 200 {object} dto.BaseResponse
+func (h *PasskeyHandler) DeleteCredential(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		dto.JSON(c, http.StatusBadRequest, nil, "Invalid id")
+		return
+	}
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	methods, err := h.auth.LoginMethods(c.Request.Context(), user)
+	if err != nil {
+		h.passkeyError(c, err)
+		return
+	}
+	if methods <= 1 {
+		h.passkeyError(c, auth.ErrLastLoginMethod)
+		return
+	}
+	if err := h.usecase.DeleteCredential(c.Request.Context(), user, uint(id)); err != nil {
+		h.passkeyError(c, err)
+		return
+	}
+	dto.JSON(c, http.StatusOK, nil, "")
+}
+
+func (h *PasskeyHandler) passkeyError(c *gin.Context, err error) {
+	if status, ok := auth.AccountStatus(err); ok {
+		dto.JSON(c, http.StatusForbidden, status, err.Error())
+		return
+	}
+	switch {
+	case errors.Is(err, passkey.ErrInvalidChallenge),
+		errors.Is(err, passkey.ErrInvalidCredential),
+		errors.Is(err, passkey.ErrCloneDetected):
+		dto.JSON(c, http.StatusUnauthorized, nil, err.Error())
+	case errors.Is(err, passkey.ErrCredentialNotFound):
+		dto.JSON(c, http.StatusNotFound, nil, err.Error())
+	case errors.Is(err, auth.ErrLastLoginMethod):
+		dto.JSON(c, http.StatusConflict, nil, err.Error())
+	default:
+		h.logger.Error("passkey error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+	}
+}
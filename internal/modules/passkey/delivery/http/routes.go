@@ -0,0 +1,27 @@
+package http
+
+import (
+	"time"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterPasskeyRoutes(cfg *config.Config, router *gin.RouterGroup, h *PasskeyHandler, limits *middlewares.RateLimiter) {
+	loginIP := limits.Policy("login_ip", middlewares.ByIP)
+	public := router.Group("/passkey")
+	{
+		public.POST("/login/begin", loginIP, h.BeginLogin)
+		public.POST("/login/finish", loginIP, h.FinishLogin)
+	}
+	private := router.Group("/passkey")
+	private.Use(middlewares.AuthMiddleware(cfg, h.logger))
+	{
+		self := middlewares.DenyImpersonation()
+		private.POST("/register/begin", self, middlewares.RequireRecentAuth(5*time.Minute), h.BeginRegistration)
+		private.POST("/register/finish", self, h.FinishRegistration)
+		private.GET("/credentials", h.ListCredentials)
+		private.DELETE("/credentials/:id", self, middlewares.RequireRecentAuth(5*time.Minute), h.DeleteCredential)
+	}
+}
@@ -0,0 +1,23 @@
+package passkey
+
+import "time"
+
+type LoginBeginRequest struct {
+	Phone string `json:"phone"`
+}
+
+type CredentialDTO struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func ToCredential(credential *Credential) CredentialDTO {
+	return CredentialDTO{
+		ID:         credential.ID,
+		Name:       credential.Name,
+		CreatedAt:  credential.CreatedAt,
+		LastUsedAt: credential.LastUsedAt,
+	}
+}
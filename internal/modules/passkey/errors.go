@@ -0,0 +1,10 @@
+package passkey
+
+import "errors"
+
+var (
+	ErrInvalidChallenge   = errors.New("invalid or expired challenge")
+	ErrInvalidCredential  = errors.New("invalid passkey credential")
+	ErrCredentialNotFound = errors.New("passkey not found")
+	ErrCloneDetected      = errors.New("passkey sign counter did not increase, authenticator may be cloned")
+)
@@ -0,0 +1,48 @@
+package passkey
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Ceremony string
+
+var (
+	CeremonyRegistration Ceremony = "registration"
+	CeremonyLogin        Ceremony = "login"
+)
+
+type Credential struct {
+	gorm.Model
+	UserID          uint       `gorm:"column:user_id;index"`
+	CredentialID    []byte     `gorm:"column:credential_id;uniqueIndex"`
+	PublicKey       []byte     `gorm:"column:public_key"`
+	AttestationType string     `gorm:"column:attestation_type"`
+	Transports      string     `gorm:"column:transports"`
+	AAGUID          []byte     `gorm:"column:aaguid"`
+	SignCount       uint32     `gorm:"column:sign_count"`
+	BackupEligible  bool       `gorm:"column:backup_eligible"`
+	BackupState     bool       `gorm:"column:backup_state"`
+	Name            string     `gorm:"column:name"`
+	LastUsedAt      *time.Time `gorm:"column:last_used_at"`
+}
+
+func (*Credential) TableName() string {
+	return "webauthn_credential"
+}
+
+// Session ceremoniya davomida challenge va webauthn.SessionData ni saqlaydi.
+// Challenge bir martalik, ishlatilgandan keyin o'chiriladi.
+type Session struct {
+	gorm.Model
+	Challenge string    `gorm:"column:challenge;uniqueIndex"`
+	UserID    *uint     `gorm:"column:user_id;default:null"`
+	Ceremony  Ceremony  `gorm:"column:ceremony"`
+	Data      string    `gorm:"column:data"`
+	Exp       time.Time `gorm:"column:exp"`
+}
+
+func (*Session) TableName() string {
+	return "webauthn_session"
+}
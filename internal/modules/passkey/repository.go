@@ -0,0 +1,94 @@
+package passkey
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasskeyRepository interface {
+	GetCredentials(context.Context, uint) ([]Credential, error)
+	GetCredential(context.Context, uint, uint) (*Credential, error)
+	CreateCredential(context.Context, *Credential) (*Credential, error)
+	UpdateCredential(context.Context, *Credential, map[string]any) error
+	DeleteCredential(context.Context, *Credential)
+	CreateSession(context.Context, *Session) (*Session, error)
+	TakeSession(context.Context, string, Ceremony) (*Session, error)
+	DeleteExpiredSessions(context.Context)
+}
+
+type PasskeyRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewPasskeyRepository(db *gorm.DB) PasskeyRepository {
+	return &PasskeyRepositoryImpl{
+		db: db,
+	}
+}
+
+func (p *PasskeyRepositoryImpl) GetCredentials(ctx context.Context, userID uint) ([]Credential, error) {
+	var credentials []Credential
+	if err := p.db.WithContext(ctx).Where("user_id = ?", userID).Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+func (p *PasskeyRepositoryImpl) GetCredential(ctx context.Context, userID uint, id uint) (*Credential, error) {
+	var credential Credential
+	if err := p.db.WithContext(ctx).Where("user_id = ? and id = ?", userID, id).First(&credential).Error; err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (p *PasskeyRepositoryImpl) CreateCredential(ctx context.Context, credential *Credential) (*Credential, error) {
+	if err := p.db.WithContext(ctx).Create(credential).Error; err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+func (p *PasskeyRepositoryImpl) UpdateCredential(ctx context.Context, credential *Credential, update map[string]any) error {
+	return p.db.WithContext(ctx).Model(credential).Updates(update).Error
+}
+
+func (p *PasskeyRepositoryImpl) DeleteCredential(ctx context.Context, credential *Credential) {
+	p.db.WithContext(ctx).Unscoped().Delete(credential)
+}
+
+func (p *PasskeyRepositoryImpl) CreateSession(ctx context.Context, session *Session) (*Session, error) {
+	if err := p.db.WithContext(ctx).Create(session).Error; err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// TakeSession challenge bo'yicha sessiyani topadi va darhol o'chiradi, shuning uchun
+// bitta challenge ikkinchi marta ishlatilmaydi.
+func (p *PasskeyRepositoryImpl) TakeSession(ctx context.Context, challenge string, ceremony Ceremony) (*Session, error) {
+	var session Session
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("challenge = ? and ceremony = ?", challenge, ceremony).First(&session).Error; err != nil {
+			return err
+		}
+		res := tx.Unscoped().Delete(&session)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (p *PasskeyRepositoryImpl) DeleteExpiredSessions(ctx context.Context) {
+	p.db.WithContext(ctx).Unscoped().Where("exp <= ?", time.Now()).Delete(&Session{})
+}
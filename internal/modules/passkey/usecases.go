@@ -0,0 +1,276 @@
+package passkey
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/JscorpTech/auth/internal/phone"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const sessionExp = 5 * time.Minute
+
+type PasskeyUsecase interface {
+	BeginRegistration(context.Context, *auth.User) (*protocol.CredentialCreation, error)
+	FinishRegistration(context.Context, *auth.User, string, io.Reader) (*Credential, error)
+	BeginLogin(context.Context, string) (*protocol.CredentialAssertion, error)
+	FinishLogin(context.Context, io.Reader) (*auth.User, *auth.AuthContext, error)
+	ListCredentials(context.Context, *auth.User) ([]Credential, error)
+	DeleteCredential(context.Context, *auth.User, uint) error
+}
+
+type PasskeyUsecaseImpl struct {
+	repo     PasskeyRepository
+	users    auth.AuthRepository
+	phones   phone.Parser
+	webauthn *webauthn.WebAuthn
+	logger   *zap.Logger
+}
+
+func NewPasskeyUsecase(repo PasskeyRepository, users auth.AuthRepository, phones phone.Parser, cfg *config.Config, logger *zap.Logger) (PasskeyUsecase, error) {
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:                  cfg.WebauthnRPID,
+		RPDisplayName:         cfg.WebauthnRPName,
+		RPOrigins:             cfg.WebauthnOrigin,
+		AttestationPreference: protocol.PreferNoAttestation,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementPreferred,
+			UserVerification: protocol.VerificationPreferred,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PasskeyUsecaseImpl{
+		repo:     repo,
+		users:    users,
+		phones:   phones,
+		webauthn: wa,
+		logger:   logger,
+	}, nil
+}
+
+func (p *PasskeyUsecaseImpl) loadUser(ctx context.Context, user *auth.User) (*webauthnUser, error) {
+	credentials, err := p.repo.GetCredentials(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &webauthnUser{user: user, credentials: credentials}, nil
+}
+
+func (p *PasskeyUsecaseImpl) saveSession(ctx context.Context, ceremony Ceremony, userID *uint, data *webauthn.SessionData) error {
+	p.repo.DeleteExpiredSessions(ctx)
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	exp := data.Expires
+	if exp.IsZero() {
+		exp = time.Now().Add(sessionExp)
+	}
+	_, err = p.repo.CreateSession(ctx, &Session{
+		Challenge: data.Challenge,
+		UserID:    userID,
+		Ceremony:  ceremony,
+		Data:      string(raw),
+		Exp:       exp,
+	})
+	return err
+}
+
+func (p *PasskeyUsecaseImpl) takeSession(ctx context.Context, ceremony Ceremony, challenge string) (*Session, *webauthn.SessionData, error) {
+	session, err := p.repo.TakeSession(ctx, challenge, ceremony)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidChallenge
+		}
+		return nil, nil, err
+	}
+	if time.Now().After(session.Exp) {
+		return nil, nil, ErrInvalidChallenge
+	}
+	var data webauthn.SessionData
+	if err := json.Unmarshal([]byte(session.Data), &data); err != nil {
+		return nil, nil, err
+	}
+	return session, &data, nil
+}
+
+func (p *PasskeyUsecaseImpl) BeginRegistration(ctx context.Context, user *auth.User) (*protocol.CredentialCreation, error) {
+	waUser, err := p.loadUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(waUser.credentials))
+	for _, credential := range waUser.WebAuthnCredentials() {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+	creation, data, err := p.webauthn.BeginRegistration(waUser, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return nil, err
+	}
+	if err := p.saveSession(ctx, CeremonyRegistration, &user.ID, data); err != nil {
+		return nil, err
+	}
+	return creation, nil
+}
+
+func (p *PasskeyUsecaseImpl) FinishRegistration(ctx context.Context, user *auth.User, name string, body io.Reader) (*Credential, error) {
+	parsed, err := protocol.ParseCredentialCreationResponseBody(body)
+	if err != nil {
+		p.logger.Info("invalid passkey registration", zap.Error(err))
+		return nil, ErrInvalidCredential
+	}
+	session, data, err := p.takeSession(ctx, CeremonyRegistration, parsed.Response.CollectedClientData.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID == nil || *session.UserID != user.ID {
+		return nil, ErrInvalidChallenge
+	}
+	waUser, err := p.loadUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	credential, err := p.webauthn.CreateCredential(waUser, *data, parsed)
+	if err != nil {
+		p.logger.Info("invalid passkey registration", zap.Error(err))
+		return nil, ErrInvalidCredential
+	}
+	if name == "" {
+		name = "Passkey"
+	}
+	return p.repo.CreateCredential(ctx, fromWebauthnCredential(user.ID, name, credential))
+}
+
+// BeginLogin telefon berilsa va unda passkey bo'lsa allowCredentials bilan, aks holda
+// discoverable (username-less) login boshlaydi. Resident bo'lmagan kalitlar faqat
+// allowCredentials bilan ishlaydi, shuning uchun javob shakli raqamda passkey borligini
+// ko'rsatadi: route login_ip limiti ostida, xatolik esa qaytarilmaydi.
+func (p *PasskeyUsecaseImpl) BeginLogin(ctx context.Context, phone string) (*protocol.CredentialAssertion, error) {
+	if phone, err := p.phones.Normalize(phone); err == nil {
+		if user, err := p.users.GetByPhone(ctx, phone); err == nil {
+			waUser, err := p.loadUser(ctx, user)
+			if err != nil {
+				return nil, err
+			}
+			if len(waUser.credentials) > 0 {
+				assertion, data, err := p.webauthn.BeginLogin(waUser)
+				if err != nil {
+					return nil, err
+				}
+				if err := p.saveSession(ctx, CeremonyLogin, &user.ID, data); err != nil {
+					return nil, err
+				}
+				return assertion, nil
+			}
+		}
+	}
+	assertion, data, err := p.webauthn.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.saveSession(ctx, CeremonyLogin, nil, data); err != nil {
+		return nil, err
+	}
+	return assertion, nil
+}
+
+func (p *PasskeyUsecaseImpl) FinishLogin(ctx context.Context, body io.Reader) (*auth.User, *auth.AuthContext, error) {
+	parsed, err := protocol.ParseCredentialRequestResponseBody(body)
+	if err != nil {
+		p.logger.Info("invalid passkey assertion", zap.Error(err))
+		return nil, nil, ErrInvalidCredential
+	}
+	session, data, err := p.takeSession(ctx, CeremonyLogin, parsed.Response.CollectedClientData.Challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var waUser *webauthnUser
+	var result *webauthn.Credential
+	if session.UserID != nil {
+		user, userErr := p.users.GetID(ctx, int64(*session.UserID))
+		if userErr != nil {
+			return nil, nil, ErrInvalidCredential
+		}
+		if waUser, err = p.loadUser(ctx, user); err != nil {
+			return nil, nil, err
+		}
+		result, err = p.webauthn.ValidateLogin(waUser, *data, parsed)
+	} else {
+		result, err = p.webauthn.ValidateDiscoverableLogin(func(_, handle []byte) (webauthn.User, error) {
+			userID, ok := userIDFromHandle(handle)
+			if !ok {
+				return nil, ErrInvalidCredential
+			}
+			user, err := p.users.GetID(ctx, int64(userID))
+			if err != nil {
+				return nil, err
+			}
+			waUser, err = p.loadUser(ctx, user)
+			return waUser, err
+		}, *data, parsed)
+	}
+	if err != nil {
+		p.logger.Info("invalid passkey assertion", zap.Error(err))
+		return nil, nil, ErrInvalidCredential
+	}
+
+	var stored *Credential
+	for i := range waUser.credentials {
+		if bytes.Equal(waUser.credentials[i].CredentialID, result.ID) {
+			stored = &waUser.credentials[i]
+			break
+		}
+	}
+	if stored == nil {
+		return nil, nil, ErrInvalidCredential
+	}
+	if result.Authenticator.CloneWarning {
+		p.logger.Warn("passkey clone warning",
+			zap.Uint("user_id", waUser.user.ID),
+			zap.Uint("credential_id", stored.ID),
+			zap.Uint32("stored_count", stored.SignCount),
+		)
+		return nil, nil, ErrCloneDetected
+	}
+	err = p.repo.UpdateCredential(ctx, stored, map[string]any{
+		"sign_count":   result.Authenticator.SignCount,
+		"backup_state": result.Flags.BackupState,
+		"last_used_at": time.Now(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	// User verification (PIN/biometrika) bilan passkey o'zi ko'p faktorli hisoblanadi.
+	if result.Flags.UserVerified {
+		return waUser.user, auth.NewAuthContext(auth.AcrMfa, auth.AmrHardwareKey, auth.AmrUser), nil
+	}
+	return waUser.user, auth.NewAuthContext(auth.AcrBasic, auth.AmrHardwareKey), nil
+}
+
+func (p *PasskeyUsecaseImpl) ListCredentials(ctx context.Context, user *auth.User) ([]Credential, error) {
+	return p.repo.GetCredentials(ctx, user.ID)
+}
+
+func (p *PasskeyUsecaseImpl) DeleteCredential(ctx context.Context, user *auth.User, id uint) error {
+	credential, err := p.repo.GetCredential(ctx, user.ID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCredentialNotFound
+		}
+		return err
+	}
+	p.repo.DeleteCredential(ctx, credential)
+	return nil
+}
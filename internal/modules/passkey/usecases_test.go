@@ -0,0 +1,275 @@
+package passkey
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/JscorpTech/auth/internal/phone"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost:8080"
+	testPhone  = "+998901112233"
+)
+
+// authenticator dasturiy passkey: ES256 kalit, attestation "none" va sign counter.
+type authenticator struct {
+	t            *testing.T
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+	// noUV foydalanuvchi tasdiqlanmagan (PIN yoki biometrikasiz) assertion
+	noUV bool
+}
+
+func newAuthenticator(t *testing.T) *authenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return &authenticator{t: t, key: key, credentialID: id}
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (a *authenticator) clientData(ceremony string, challenge protocol.URLEncodedBase64) []byte {
+	data, _ := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": b64(challenge),
+		"origin":    testOrigin,
+	})
+	return data
+}
+
+// authData flags: UP (0x01) va UV (0x04), attested bo'lsa AT (0x40).
+func (a *authenticator) authData(attested bool) []byte {
+	rpHash := sha256.Sum256([]byte(testRPID))
+	data := append([]byte{}, rpHash[:]...)
+	flags := byte(0x05)
+	if attested {
+		flags |= 0x40
+	} else if a.noUV {
+		flags &^= 0x04
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if !attested {
+		return data
+	}
+	data = append(data, make([]byte, 16)...)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+	data = append(data, a.credentialID...)
+	coseKey, err := webauthncbor.Marshal(map[int]any{
+		1:  2,
+		3:  -7,
+		-1: 1,
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return append(data, coseKey...)
+}
+
+func (a *authenticator) create(creation *protocol.CredentialCreation) []byte {
+	a.userHandle = creation.Response.User.ID.(protocol.URLEncodedBase64)
+	attestation, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": a.authData(true),
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	body, _ := json.Marshal(map[string]any{
+		"id":    b64(a.credentialID),
+		"rawId": b64(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    b64(a.clientData("webauthn.create", creation.Response.Challenge)),
+			"attestationObject": b64(attestation),
+		},
+	})
+	return body
+}
+
+func (a *authenticator) get(assertion *protocol.CredentialAssertion) []byte {
+	a.signCount++
+	clientData := a.clientData("webauthn.get", assertion.Response.Challenge)
+	authData := a.authData(false)
+	clientHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	body, _ := json.Marshal(map[string]any{
+		"id":    b64(a.credentialID),
+		"rawId": b64(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    b64(clientData),
+			"authenticatorData": b64(authData),
+			"signature":         b64(signature),
+			"userHandle":        b64(a.userHandle),
+		},
+	})
+	return body
+}
+
+func setup(t *testing.T) (PasskeyUsecase, *auth.User) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&auth.User{}, &Credential{}, &Session{}); err != nil {
+		t.Fatal(err)
+	}
+	phoneNumber := testPhone
+	user := &auth.User{Phone: &phoneNumber, FirstName: "Test"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		WebauthnRPID:   testRPID,
+		WebauthnRPName: "Test",
+		WebauthnOrigin: []string{testOrigin},
+		PhoneParser:    "e164",
+	}
+	parser, err := phone.NewParser(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	usecase, err := NewPasskeyUsecase(NewPasskeyRepository(db), auth.NewAuthRepository(db), parser, cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return usecase, user
+}
+
+func register(t *testing.T, usecase PasskeyUsecase, user *auth.User, key *authenticator) {
+	ctx := context.Background()
+	creation, err := usecase.BeginRegistration(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := usecase.FinishRegistration(ctx, user, "test", bytes.NewReader(key.create(creation))); err != nil {
+		t.Fatalf("finish registration: %v", err)
+	}
+}
+
+func TestPasskeyRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	usecase, user := setup(t)
+	key := newAuthenticator(t)
+	register(t, usecase, user, key)
+
+	// telefon bo'yicha (allowCredentials) va discoverable login
+	for _, loginPhone := range []string{testPhone, ""} {
+		assertion, err := usecase.BeginLogin(ctx, loginPhone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, authCtx, err := usecase.FinishLogin(ctx, bytes.NewReader(key.get(assertion)))
+		if err != nil {
+			t.Fatalf("finish login (phone %q): %v", loginPhone, err)
+		}
+		if got.ID != user.ID {
+			t.Fatalf("logged in as %d, want %d", got.ID, user.ID)
+		}
+		if authCtx.Acr != auth.AcrMfa {
+			t.Fatalf("acr = %q, want %q", authCtx.Acr, auth.AcrMfa)
+		}
+	}
+
+	credentials, err := usecase.ListCredentials(ctx, user)
+	if err != nil || len(credentials) != 1 {
+		t.Fatalf("credentials = %v, %v", credentials, err)
+	}
+	if credentials[0].SignCount != key.signCount {
+		t.Fatalf("sign count = %d, want %d", credentials[0].SignCount, key.signCount)
+	}
+}
+
+func TestPasskeyLoginWithoutUserVerificationIsSingleFactor(t *testing.T) {
+	ctx := context.Background()
+	usecase, user := setup(t)
+	key := newAuthenticator(t)
+	register(t, usecase, user, key)
+
+	key.noUV = true
+	assertion, err := usecase.BeginLogin(ctx, testPhone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, authCtx, err := usecase.FinishLogin(ctx, bytes.NewReader(key.get(assertion)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authCtx.Acr != auth.AcrBasic {
+		t.Fatalf("acr = %q, want %q", authCtx.Acr, auth.AcrBasic)
+	}
+}
+
+func TestPasskeyLoginRejectsInvalidSignature(t *testing.T) {
+	ctx := context.Background()
+	usecase, user := setup(t)
+	key := newAuthenticator(t)
+	register(t, usecase, user, key)
+
+	for _, loginPhone := range []string{testPhone, ""} {
+		assertion, err := usecase.BeginLogin(ctx, loginPhone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// boshqa kalit bilan imzolangan assertion
+		forged := *key
+		forged.key = newAuthenticator(t).key
+		_, _, err = usecase.FinishLogin(ctx, bytes.NewReader(forged.get(assertion)))
+		if !errors.Is(err, ErrInvalidCredential) {
+			t.Fatalf("phone %q: err = %v, want %v", loginPhone, err, ErrInvalidCredential)
+		}
+	}
+}
+
+func TestPasskeyLoginDetectsClone(t *testing.T) {
+	ctx := context.Background()
+	usecase, user := setup(t)
+	key := newAuthenticator(t)
+	register(t, usecase, user, key)
+
+	key.signCount = 10
+	assertion, _ := usecase.BeginLogin(ctx, testPhone)
+	if _, _, err := usecase.FinishLogin(ctx, bytes.NewReader(key.get(assertion))); err != nil {
+		t.Fatal(err)
+	}
+	// nusxa eski hisoblagich bilan imzolaydi
+	key.signCount = 5
+	assertion, _ = usecase.BeginLogin(ctx, testPhone)
+	if _, _, err := usecase.FinishLogin(ctx, bytes.NewReader(key.get(assertion))); !errors.Is(err, ErrCloneDetected) {
+		t.Fatalf("err = %v, want %v", err, ErrCloneDetected)
+	}
+}
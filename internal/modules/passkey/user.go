@@ -0,0 +1,104 @@
+package passkey
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// webauthnUser auth.User ni webauthn.User interfeysiga moslaydi.
+type webauthnUser struct {
+	user        *auth.User
+	credentials []Credential
+}
+
+var _ webauthn.User = (*webauthnUser)(nil)
+
+func userHandle(userID uint) []byte {
+	handle := make([]byte, 8)
+	binary.BigEndian.PutUint64(handle, uint64(userID))
+	return handle
+}
+
+func userIDFromHandle(handle []byte) (uint, bool) {
+	if len(handle) != 8 {
+		return 0, false
+	}
+	return uint(binary.BigEndian.Uint64(handle)), true
+}
+
+func (u *webauthnUser) WebAuthnID() []byte {
+	return userHandle(u.user.ID)
+}
+
+func (u *webauthnUser) WebAuthnName() string {
+	switch {
+	case u.user.Phone != nil:
+		return *u.user.Phone
+	case u.user.Email != nil:
+		return *u.user.Email
+	default:
+		return u.WebAuthnDisplayName()
+	}
+}
+
+func (u *webauthnUser) WebAuthnDisplayName() string {
+	return strings.TrimSpace(u.user.FirstName + " " + u.user.LastName)
+}
+
+func (u *webauthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *webauthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.credentials))
+	for _, credential := range u.credentials {
+		credentials = append(credentials, toWebauthnCredential(&credential))
+	}
+	return credentials
+}
+
+func toWebauthnCredential(credential *Credential) webauthn.Credential {
+	var transports []protocol.AuthenticatorTransport
+	if credential.Transports != "" {
+		for _, transport := range strings.Split(credential.Transports, ",") {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+	}
+	return webauthn.Credential{
+		ID:              credential.CredentialID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: credential.BackupEligible,
+			BackupState:    credential.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    credential.AAGUID,
+			SignCount: credential.SignCount,
+		},
+	}
+}
+
+func fromWebauthnCredential(userID uint, name string, credential *webauthn.Credential) *Credential {
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+	return &Credential{
+		UserID:          userID,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      strings.Join(transports, ","),
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		Name:            name,
+	}
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/dto"
+	"github.com/JscorpTech/auth/internal/modules/smsguard"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SmsGuardHandler struct {
+	usecase smsguard.SmsGuardUsecase
+	logger  *zap.Logger
+}
+
+func NewSmsGuardHandler(usecase smsguard.SmsGuardUsecase, logger *zap.Logger) *SmsGuardHandler {
+	return &SmsGuardHandler{
+		usecase: usecase,
+		logger:  logger,
+	}
+}
+
+// @Router /api/v1/auth/admin/sms/stats [get]
+// @Summary OTP send statistics
+// @Description Total sends and top channels, prefixes, IPs and phones for the period.
+// @Tags admin
+// @Produce json
+// @Param hours query int false "Period in hours" default(24)
+// @Param top query int false "Items per group" default(10)
+// @Success 200 {object} dto.BaseResponse{data=smsguard.StatsDTO}
+func (h *SmsGuardHandler) Stats(c *gin.Context) {
+	var query smsguard.StatsRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &query), "Invalid request")
+		return
+	}
+	query.Normalize()
+	since := time.Now().Add(-time.Duration(query.Hours) * time.Hour)
+	stats, err := h.usecase.Stats(c.Request.Context(), since, query.Top)
+	if err != nil {
+		h.logger.Error("sms stats error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		return
+	}
+	dto.JSON(c, http.StatusOK, smsguard.ToStats(stats), "")
+}
+
+// @Router /api/v1/auth/admin/sms/limits [get]
+// @Summary OTP send limits
+// @Tags admin
+// @Produce json
+// @Success 200 {object} dto.BaseResponse{data=smsguard.LimitsDTO}
+func (h *SmsGuardHandler) GetLimits(c *gin.Context) {
+	limits, err := h.usecase.Limits(c.Request.Context())
+	if err != nil {
+		h.logger.Error("sms limits error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		return
+	}
+	dto.JSON(c, http.StatusOK, smsguard.ToLimits(limits), "")
+}
+
+// @Router /api/v1/auth/admin/sms/limits [put]
+// @Summary Replace OTP send limits
+// @Description Daily caps per phone, IP and prefix (0 disables), blocked prefixes, allowed country codes and spike alert settings. All fields are required.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body smsguard.LimitsRequest true "Limits"
+// @Success 200 {object} dto.BaseResponse{data=smsguard.LimitsDTO}
+func (h *SmsGuardHandler) UpdateLimits(c *gin.Context) {
+	var payload smsguard.LimitsRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		dto.JSON(c, http.StatusBadRequest, utils.FormatValidationErrors(err, &payload), "Invalid request")
+		return
+	}
+	limits := payload.Model()
+	if err := h.usecase.UpdateLimits(c.Request.Context(), limits); err != nil {
+		h.logger.Error("sms limits update error", zap.Error(err))
+		dto.JSON(c, http.StatusInternalServerError, nil, "Internal Server Error")
+		return
+	}
+	dto.JSON(c, http.StatusOK, smsguard.ToLimits(limits), "")
+}
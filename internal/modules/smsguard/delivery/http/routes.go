@@ -0,0 +1,26 @@
+package http
+
+import (
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/middlewares"
+	"github.com/JscorpTech/auth/internal/modules/auth"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterSmsGuardRoutes(cfg *config.Config, router *gin.RouterGroup, h *SmsGuardHandler, users middlewares.UserLoader) {
+	admin := router.Group("/admin/sms")
+	admin.Use(
+		middlewares.AdminIPFilter(cfg),
+		middlewares.AuthMiddleware(cfg, h.logger),
+		middlewares.ActiveUser(users),
+		middlewares.DenyImpersonation(),
+		middlewares.RequireRole(auth.RoleAdmin, auth.RoleSuper),
+		middlewares.RequireACR(auth.AcrMfa),
+		middlewares.RequirePermission(auth.PermSmsManage),
+	)
+	{
+		admin.GET("/stats", h.Stats)
+		admin.GET("/limits", h.GetLimits)
+		admin.PUT("/limits", h.UpdateLimits)
+	}
+}
@@ -0,0 +1,106 @@
+package smsguard
+
+import (
+	"strings"
+	"time"
+)
+
+type StatsRequest struct {
+	Hours int `form:"hours" binding:"omitempty,min=1,max=720"`
+	Top   int `form:"top" binding:"omitempty,min=1,max=100"`
+}
+
+func (r *StatsRequest) Normalize() {
+	if r.Hours == 0 {
+		r.Hours = 24
+	}
+	if r.Top == 0 {
+		r.Top = 10
+	}
+}
+
+type CountDTO struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type StatsDTO struct {
+	From     time.Time  `json:"from"`
+	Total    int64      `json:"total"`
+	Channels []CountDTO `json:"channels"`
+	Prefixes []CountDTO `json:"prefixes"`
+	IPs      []CountDTO `json:"ips"`
+	Phones   []CountDTO `json:"phones"`
+}
+
+type LimitsDTO struct {
+	PhoneDaily       int64    `json:"phone_daily"`
+	IPDaily          int64    `json:"ip_daily"`
+	PrefixDaily      int64    `json:"prefix_daily"`
+	BlockedPrefixes  []string `json:"blocked_prefixes"`
+	AllowedCountries []string `json:"allowed_countries"`
+	AlertFactor      float64  `json:"alert_factor"`
+	AlertMin         int64    `json:"alert_min"`
+}
+
+// LimitsRequest limitlarni to'liq almashtiradi, 0 limitni o'chiradi. Barcha maydonlar
+// majburiy: tushirib qoldirilgan maydon jimgina 0 bo'lib limitni o'chirib qo'ymasin.
+type LimitsRequest struct {
+	PhoneDaily       *int64   `json:"phone_daily" binding:"required,min=0"`
+	IPDaily          *int64   `json:"ip_daily" binding:"required,min=0"`
+	PrefixDaily      *int64   `json:"prefix_daily" binding:"required,min=0"`
+	BlockedPrefixes  []string `json:"blocked_prefixes" binding:"required,dive,numeric"`
+	AllowedCountries []string `json:"allowed_countries" binding:"required,dive,numeric"`
+	AlertFactor      *float64 `json:"alert_factor" binding:"required,min=0"`
+	AlertMin         *int64   `json:"alert_min" binding:"required,min=0"`
+}
+
+func (l *LimitsRequest) Model() *Limits {
+	return &Limits{
+		PhoneDaily:       *l.PhoneDaily,
+		IPDaily:          *l.IPDaily,
+		PrefixDaily:      *l.PrefixDaily,
+		BlockedPrefixes:  strings.Join(l.BlockedPrefixes, ","),
+		AllowedCountries: strings.Join(l.AllowedCountries, ","),
+		AlertFactor:      *l.AlertFactor,
+		AlertMin:         *l.AlertMin,
+	}
+}
+
+func ToLimits(limits *Limits) LimitsDTO {
+	return LimitsDTO{
+		PhoneDaily:       limits.PhoneDaily,
+		IPDaily:          limits.IPDaily,
+		PrefixDaily:      limits.PrefixDaily,
+		BlockedPrefixes:  listOrEmpty(splitList(limits.BlockedPrefixes)),
+		AllowedCountries: listOrEmpty(splitList(limits.AllowedCountries)),
+		AlertFactor:      limits.AlertFactor,
+		AlertMin:         limits.AlertMin,
+	}
+}
+
+func ToStats(stats *Stats) StatsDTO {
+	return StatsDTO{
+		From:     stats.From,
+		Total:    stats.Total,
+		Channels: toCounts(stats.Channels),
+		Prefixes: toCounts(stats.Prefixes),
+		IPs:      toCounts(stats.IPs),
+		Phones:   toCounts(stats.Phones),
+	}
+}
+
+func toCounts(counts []Count) []CountDTO {
+	items := make([]CountDTO, 0, len(counts))
+	for _, count := range counts {
+		items = append(items, CountDTO{Value: count.Value, Count: count.Count})
+	}
+	return items
+}
+
+func listOrEmpty(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
@@ -0,0 +1,8 @@
+package smsguard
+
+import "errors"
+
+var (
+	ErrNumberNotAllowed = errors.New("sending SMS to this number is not allowed")
+	ErrDailyLimit       = errors.New("daily SMS limit exceeded")
+)
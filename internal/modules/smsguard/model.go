@@ -0,0 +1,43 @@
+package smsguard
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChannelSms kunlik limitlar va anomaliya faqat shu kanaldagi yuborishlarni sanaydi,
+// Telegram orqali yuborilgan kodlar faqat statistikada ko'rinadi.
+const ChannelSms = "sms"
+
+// Send bitta yuborilgan OTP. Kunlik limitlar va statistika shu jadvaldan hisoblanadi.
+type Send struct {
+	gorm.Model
+	Phone   string `gorm:"column:phone;index"`
+	IP      string `gorm:"column:ip;index"`
+	Prefix  string `gorm:"column:prefix;index"`
+	Channel string `gorm:"column:channel"`
+}
+
+func (*Send) TableName() string {
+	return "sms_send"
+}
+
+// Limits admin o'zgartirgan limitlar, bitta qator. Yozuv bo'lmasa config dagi qiymatlar
+// ishlatiladi. 0 limitni o'chiradi.
+type Limits struct {
+	ID          uint  `gorm:"primarykey"`
+	PhoneDaily  int64 `gorm:"column:phone_daily"`
+	IPDaily     int64 `gorm:"column:ip_daily"`
+	PrefixDaily int64 `gorm:"column:prefix_daily"`
+	// BlockedPrefixes va AllowedCountries vergul bilan ajratilgan raqam prefikslari
+	BlockedPrefixes  string  `gorm:"column:blocked_prefixes"`
+	AllowedCountries string  `gorm:"column:allowed_countries"`
+	AlertFactor      float64 `gorm:"column:alert_factor"`
+	AlertMin         int64   `gorm:"column:alert_min"`
+	UpdatedAt        time.Time
+}
+
+func (*Limits) TableName() string {
+	return "sms_limits"
+}
@@ -0,0 +1,106 @@
+package smsguard
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SendFilter bo'sh maydonlar hisobga olinmaydi.
+type SendFilter struct {
+	Phone   string
+	IP      string
+	Prefix  string
+	Channel string
+	From    time.Time
+	To      time.Time
+}
+
+type Count struct {
+	Value string
+	Count int64
+}
+
+// Statistikada guruhlanadigan ustunlar.
+const (
+	ColumnPhone   = "phone"
+	ColumnIP      = "ip"
+	ColumnPrefix  = "prefix"
+	ColumnChannel = "channel"
+)
+
+type SmsGuardRepository interface {
+	Create(context.Context, *Send) error
+	Delete(context.Context, *Send) error
+	Count(context.Context, SendFilter) (int64, error)
+	Top(ctx context.Context, column string, since time.Time, limit int) ([]Count, error)
+	GetLimits(context.Context) (*Limits, error)
+	SaveLimits(context.Context, *Limits) error
+}
+
+type SmsGuardRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewSmsGuardRepository(db *gorm.DB) SmsGuardRepository {
+	return &SmsGuardRepositoryImpl{
+		db: db,
+	}
+}
+
+func (s *SmsGuardRepositoryImpl) Create(ctx context.Context, send *Send) error {
+	return s.db.WithContext(ctx).Create(send).Error
+}
+
+func (s *SmsGuardRepositoryImpl) Delete(ctx context.Context, send *Send) error {
+	return s.db.WithContext(ctx).Unscoped().Delete(send).Error
+}
+
+func (s *SmsGuardRepositoryImpl) Count(ctx context.Context, filter SendFilter) (int64, error) {
+	query := s.db.WithContext(ctx).Model(&Send{}).Where("created_at >= ?", filter.From)
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	if filter.Phone != "" {
+		query = query.Where("phone = ?", filter.Phone)
+	}
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+	if filter.Prefix != "" {
+		query = query.Where("prefix = ?", filter.Prefix)
+	}
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// Top column bo'yicha eng ko'p yuborilgan qiymatlar. column faqat Column* konstantalaridan biri.
+func (s *SmsGuardRepositoryImpl) Top(ctx context.Context, column string, since time.Time, limit int) ([]Count, error) {
+	var counts []Count
+	err := s.db.WithContext(ctx).Model(&Send{}).
+		Select(column+" AS value, COUNT(*) AS count").
+		Where("created_at >= ?", since).
+		Group(column).
+		Order("count desc").
+		Limit(limit).
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (s *SmsGuardRepositoryImpl) GetLimits(ctx context.Context) (*Limits, error) {
+	var limits Limits
+	if err := s.db.WithContext(ctx).First(&limits).Error; err != nil {
+		return nil, err
+	}
+	return &limits, nil
+}
+
+func (s *SmsGuardRepositoryImpl) SaveLimits(ctx context.Context, limits *Limits) error {
+	limits.ID = 1
+	return s.db.WithContext(ctx).Save(limits).Error
+}
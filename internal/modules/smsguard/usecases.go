@@ -0,0 +1,277 @@
+package smsguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/JscorpTech/auth/internal/modules/audit"
+	"github.com/JscorpTech/auth/internal/sms"
+	"github.com/JscorpTech/auth/pkg/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	day = 24 * time.Hour
+	// baselineDays anomaliya uchun oxirgi soat shu kunlardagi o'rtacha soat bilan solishtiriladi
+	baselineDays = 7
+)
+
+type Stats struct {
+	From     time.Time
+	Total    int64
+	Channels []Count
+	Prefixes []Count
+	IPs      []Count
+	Phones   []Count
+}
+
+// SmsGuardUsecase SMS pumping dan himoya: OTP yuborishdan oldin Reserve, yuborilmasa
+// Release, yuborilgandan keyin Record.
+type SmsGuardUsecase interface {
+	Reserve(ctx context.Context, phone string, channel string) (*Send, error)
+	Release(ctx context.Context, send *Send)
+	Record(ctx context.Context, send *Send)
+	Stats(ctx context.Context, since time.Time, top int) (*Stats, error)
+	Limits(context.Context) (*Limits, error)
+	UpdateLimits(context.Context, *Limits) error
+}
+
+type SmsGuardUsecaseImpl struct {
+	repo  SmsGuardRepository
+	audit audit.AuditUsecase
+	// email anomaliya haqida SMS_ALERT_EMAILS ga xabar yuborish uchun
+	email    sms.SmsProvider
+	defaults Limits
+	cfg      *config.Config
+	logger   *zap.Logger
+
+	mu      sync.Mutex
+	alerted map[string]time.Time
+}
+
+func NewSmsGuardUsecase(repo SmsGuardRepository, auditUsecase audit.AuditUsecase, email sms.SmsProvider, cfg *config.Config, logger *zap.Logger) SmsGuardUsecase {
+	return &SmsGuardUsecaseImpl{
+		repo:  repo,
+		audit: auditUsecase,
+		email: email,
+		defaults: Limits{
+			PhoneDaily:       cfg.SmsPhoneDailyLimit,
+			IPDaily:          cfg.SmsIPDailyLimit,
+			PrefixDaily:      cfg.SmsPrefixDailyLimit,
+			BlockedPrefixes:  strings.Join(cfg.SmsBlockedPrefixes, ","),
+			AllowedCountries: strings.Join(cfg.SmsAllowedCountries, ","),
+			AlertFactor:      cfg.SmsAlertFactor,
+			AlertMin:         cfg.SmsAlertMin,
+		},
+		cfg:     cfg,
+		logger:  logger,
+		alerted: make(map[string]time.Time),
+	}
+}
+
+// Reserve raqam ruxsat etilgan mamlakatga tegishli va bloklangan prefiksda emasligini
+// tekshiradi, keyin yuborishni oldindan yozib oxirgi 24 soatdagi raqam, IP va prefiks
+// limitlarini sanaydi. Yozuv sanashdan oldin saqlangani uchun parallel so'rovlar bir-birini
+// ko'radi va limitdan oshib ketolmaydi. SMS dan boshqa kanal faqat yoziladi, tekshiruv va
+// limitlar unga qo'llanmaydi.
+func (s *SmsGuardUsecaseImpl) Reserve(ctx context.Context, phone string, channel string) (*Send, error) {
+	send := &Send{
+		Phone:   phone,
+		IP:      audit.ClientFromContext(ctx).IP,
+		Prefix:  s.prefix(phone),
+		Channel: channel,
+	}
+	if channel != ChannelSms {
+		if err := s.repo.Create(ctx, send); err != nil {
+			return nil, err
+		}
+		return send, nil
+	}
+	limits, err := s.Limits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	digits := utils.PhoneDigits(phone)
+	if countries := splitList(limits.AllowedCountries); len(countries) > 0 && !hasAnyPrefix(digits, countries) {
+		s.blocked(ctx, phone, "country")
+		return nil, ErrNumberNotAllowed
+	}
+	if hasAnyPrefix(digits, splitList(limits.BlockedPrefixes)) {
+		s.blocked(ctx, phone, "blocked_prefix")
+		return nil, ErrNumberNotAllowed
+	}
+	if err := s.repo.Create(ctx, send); err != nil {
+		return nil, err
+	}
+	since := time.Now().Add(-day)
+	checks := []struct {
+		name   string
+		limit  int64
+		filter SendFilter
+	}{
+		{"phone", limits.PhoneDaily, SendFilter{Phone: phone, Channel: ChannelSms, From: since}},
+		{"ip", limits.IPDaily, SendFilter{IP: send.IP, Channel: ChannelSms, From: since}},
+		{"prefix", limits.PrefixDaily, SendFilter{Prefix: send.Prefix, Channel: ChannelSms, From: since}},
+	}
+	for _, check := range checks {
+		if check.limit <= 0 || (check.name == "ip" && check.filter.IP == "") {
+			continue
+		}
+		// hisobga shu yozuv ham kiradi
+		count, err := s.repo.Count(ctx, check.filter)
+		if err != nil {
+			s.Release(ctx, send)
+			return nil, err
+		}
+		if count > check.limit {
+			s.Release(ctx, send)
+			s.blocked(ctx, phone, check.name+"_limit")
+			return nil, ErrDailyLimit
+		}
+	}
+	return send, nil
+}
+
+// Release yuborilmagan OTP yozuvini o'chiradi, u limitlarga hisoblanmaydi.
+func (s *SmsGuardUsecaseImpl) Release(ctx context.Context, send *Send) {
+	if err := s.repo.Delete(context.WithoutCancel(ctx), send); err != nil {
+		s.logger.Error("sms send release error", zap.Error(err))
+	}
+}
+
+func (s *SmsGuardUsecaseImpl) blocked(ctx context.Context, phone string, reason string) {
+	s.logger.Warn("otp send blocked", zap.String("phone", phone), zap.String("reason", reason))
+	s.audit.Record(ctx, audit.Entry{
+		Type:     audit.TypeOtpBlocked,
+		Metadata: map[string]any{"phone": phone, "reason": reason},
+	})
+}
+
+// Record yuborilgan SMS dan keyin prefiks bo'yicha anomaliyani tekshiradi. Xatolar faqat
+// logga tushadi.
+func (s *SmsGuardUsecaseImpl) Record(ctx context.Context, send *Send) {
+	if send.Channel != ChannelSms {
+		return
+	}
+	if err := s.detectAnomaly(context.WithoutCancel(ctx), send.Prefix); err != nil {
+		s.logger.Error("sms anomaly check error", zap.Error(err))
+	}
+}
+
+// detectAnomaly oxirgi soatda prefiksga yuborishlar AlertMin dan kam bo'lmasa va oldingi
+// haftaning o'rtacha soatidan AlertFactor marta ko'p bo'lsa ogohlantiradi, har prefiks uchun soatiga bir marta.
+func (s *SmsGuardUsecaseImpl) detectAnomaly(ctx context.Context, prefix string) error {
+	limits, err := s.Limits(ctx)
+	if err != nil || limits.AlertFactor <= 0 {
+		return err
+	}
+	now := time.Now()
+	hour, err := s.repo.Count(ctx, SendFilter{Prefix: prefix, Channel: ChannelSms, From: now.Add(-time.Hour)})
+	if err != nil || hour < limits.AlertMin {
+		return err
+	}
+	previous, err := s.repo.Count(ctx, SendFilter{Prefix: prefix, Channel: ChannelSms, From: now.Add(-baselineDays * day), To: now.Add(-time.Hour)})
+	if err != nil {
+		return err
+	}
+	baseline := float64(previous) / float64(baselineDays*24)
+	if float64(hour) < baseline*limits.AlertFactor {
+		return nil
+	}
+	s.mu.Lock()
+	if last, ok := s.alerted[prefix]; ok && now.Sub(last) < time.Hour {
+		s.mu.Unlock()
+		return nil
+	}
+	s.alerted[prefix] = now
+	s.mu.Unlock()
+
+	s.logger.Warn("sms send volume spike", zap.String("prefix", prefix), zap.Int64("last_hour", hour), zap.Float64("baseline", baseline))
+	s.audit.Record(ctx, audit.Entry{
+		Type:     audit.TypeSmsAnomaly,
+		Metadata: map[string]any{"prefix": prefix, "last_hour": hour, "baseline": baseline},
+	})
+	msg := fmt.Sprintf("SMS yuborishlar keskin oshdi: prefiks %s, oxirgi soatda %d ta (odatda %.1f ta)", prefix, hour, baseline)
+	for _, to := range s.cfg.SmsAlertEmails {
+		if err := s.email.Send(to, msg); err != nil {
+			s.logger.Error("sms anomaly alert error", zap.String("to", to), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (s *SmsGuardUsecaseImpl) Stats(ctx context.Context, since time.Time, top int) (*Stats, error) {
+	total, err := s.repo.Count(ctx, SendFilter{From: since})
+	if err != nil {
+		return nil, err
+	}
+	stats := &Stats{From: since, Total: total}
+	for column, out := range map[string]*[]Count{
+		ColumnChannel: &stats.Channels,
+		ColumnPrefix:  &stats.Prefixes,
+		ColumnIP:      &stats.IPs,
+		ColumnPhone:   &stats.Phones,
+	} {
+		if *out, err = s.repo.Top(ctx, column, since, top); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+func (s *SmsGuardUsecaseImpl) Limits(ctx context.Context) (*Limits, error) {
+	limits, err := s.repo.GetLimits(ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		defaults := s.defaults
+		return &defaults, nil
+	}
+	return limits, err
+}
+
+func (s *SmsGuardUsecaseImpl) UpdateLimits(ctx context.Context, limits *Limits) error {
+	if err := s.repo.SaveLimits(ctx, limits); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, audit.Entry{
+		Type: audit.TypeSmsLimitsChanged,
+		Metadata: map[string]any{
+			"phone_daily":       limits.PhoneDaily,
+			"ip_daily":          limits.IPDaily,
+			"prefix_daily":      limits.PrefixDaily,
+			"blocked_prefixes":  splitList(limits.BlockedPrefixes),
+			"allowed_countries": splitList(limits.AllowedCountries),
+			"alert_factor":      limits.AlertFactor,
+			"alert_min":         limits.AlertMin,
+		},
+	})
+	return nil
+}
+
+func (s *SmsGuardUsecaseImpl) prefix(phone string) string {
+	return utils.PhonePrefix(phone, int(s.cfg.SmsPrefixLength))
+}
+
+func hasAnyPrefix(digits string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(digits, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = utils.PhoneDigits(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
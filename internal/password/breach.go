@@ -0,0 +1,93 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type BreachChecker interface {
+	Breached(string) (bool, error)
+}
+
+// BreachCorpus Have I Been Pwned k-anonymity formatidagi mahalliy korpus. SHA-1 ning
+// birinchi 5 belgisi prefix, qolgan 35 tasi "SUFFIX:COUNT" qatorlarida saqlanadi.
+//
+// Path katalog bo'lsa har bir prefix alohida faylda (range API javobi kabi) bo'ladi va
+// faqat kerakli fayl o'qiladi. Oddiy fayl bo'lsa "HASH:COUNT" qatorlari xotiraga yuklanadi.
+type BreachCorpus struct {
+	dir    string
+	hashes map[string]map[string]struct{}
+}
+
+func NewBreachCorpus(path string) (*BreachCorpus, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &BreachCorpus{dir: path}, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	corpus := &BreachCorpus{hashes: make(map[string]map[string]struct{})}
+	err = scanHashes(file, func(hash string) {
+		if len(hash) != sha1.Size*2 {
+			return
+		}
+		prefix, suffix := hash[:5], hash[5:]
+		if corpus.hashes[prefix] == nil {
+			corpus.hashes[prefix] = make(map[string]struct{})
+		}
+		corpus.hashes[prefix][suffix] = struct{}{}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return corpus, nil
+}
+
+func (b *BreachCorpus) Breached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+	if b.dir == "" {
+		_, ok := b.hashes[prefix][suffix]
+		return ok, nil
+	}
+	file, err := os.Open(filepath.Join(b.dir, prefix))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+	found := false
+	err = scanHashes(file, func(value string) {
+		if value == suffix {
+			found = true
+		}
+	})
+	return found, err
+}
+
+// scanHashes har bir qatordagi ":COUNT" gacha bo'lgan hashni katta harflarda beradi.
+func scanHashes(reader io.Reader, fn func(string)) error {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if hash != "" {
+			fn(strings.ToUpper(hash))
+		}
+	}
+	return scanner.Err()
+}
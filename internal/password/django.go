@@ -0,0 +1,160 @@
+package password
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Django 5.2 hasherlarining standart parametrlari. Django must_update da parametrlarni
+// tenglik bilan solishtiradi, shuning uchun Django formatidagi hashlar aynan shular bilan yaratiladi.
+const (
+	djangoPbkdf2Iterations = 1000000
+	djangoArgon2Memory     = 102400
+	djangoArgon2Time       = 2
+	djangoArgon2Threads    = 8
+	djangoBcryptRounds     = 12
+	// djangoSaltLength 62 belgili alifboda 128 bit entropiya uchun kerakli uzunlik
+	djangoSaltLength = 22
+)
+
+// DjangoPbkdf2 Django ning standart formati: pbkdf2_sha256$iterations$salt$base64(hash).
+type DjangoPbkdf2 struct {
+	Iterations int
+}
+
+func (d *DjangoPbkdf2) Name() string {
+	return "pbkdf2_sha256"
+}
+
+func (d *DjangoPbkdf2) Identify(encoded string) bool {
+	return strings.HasPrefix(encoded, "pbkdf2_sha256$")
+}
+
+func (d *DjangoPbkdf2) Hash(password string) (string, error) {
+	salt := secureSalt()
+	key, err := pbkdf2.Key(sha256.New, password, []byte(salt), d.Iterations, sha256.Size)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pbkdf2_sha256$%d$%s$%s", d.Iterations, salt, base64.StdEncoding.EncodeToString(key)), nil
+}
+
+func (d *DjangoPbkdf2) Verify(password string, encoded string) (bool, error) {
+	iterations, salt, hash, err := parseDjangoPbkdf2(encoded)
+	if err != nil {
+		return false, err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, []byte(salt), iterations, len(hash))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(key, hash) == 1, nil
+}
+
+func (d *DjangoPbkdf2) NeedsRehash(encoded string) bool {
+	iterations, salt, _, err := parseDjangoPbkdf2(encoded)
+	return err != nil || iterations != d.Iterations || len(salt) < djangoSaltLength
+}
+
+func parseDjangoPbkdf2(encoded string) (int, string, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2_sha256" {
+		return 0, "", nil, ErrUnknownHash
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return 0, "", nil, ErrUnknownHash
+	}
+	hash, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil || len(hash) == 0 {
+		return 0, "", nil, ErrUnknownHash
+	}
+	return iterations, parts[2], hash, nil
+}
+
+// DjangoArgon2 Argon2PasswordHasher formati: "argon2" + PHC qatori. Asosiy algoritm
+// sifatida tanlansa Django ilovasi ham yangilangan hashlarni tekshira oladi.
+type DjangoArgon2 struct {
+	Argon2id
+}
+
+func (d *DjangoArgon2) Name() string {
+	return "django_argon2"
+}
+
+func (d *DjangoArgon2) Identify(encoded string) bool {
+	return strings.HasPrefix(encoded, "argon2$")
+}
+
+// Hash Django kabi 22 belgili salt qatorining baytlaridan foydalanadi.
+func (d *DjangoArgon2) Hash(password string) (string, error) {
+	return "argon2" + d.Argon2id.hash(password, []byte(secureSalt())), nil
+}
+
+func (d *DjangoArgon2) Verify(password string, encoded string) (bool, error) {
+	params, err := parseArgon2(strings.TrimPrefix(encoded, "argon2"))
+	if err != nil {
+		return false, err
+	}
+	return params.verify(password), nil
+}
+
+func (d *DjangoArgon2) NeedsRehash(encoded string) bool {
+	params, err := parseArgon2(strings.TrimPrefix(encoded, "argon2"))
+	if err != nil {
+		return true
+	}
+	return params.variant != "argon2id" || params.memory != d.Memory || params.time != d.Time ||
+		params.threads != d.Threads || len(params.key) != argon2KeyLength ||
+		len(base64.RawStdEncoding.EncodeToString(params.salt)) < djangoSaltLength
+}
+
+// DjangoBcryptSHA256 72 bayt cheklovini chetlab o'tish uchun parolning SHA-256 hex
+// digesti bcrypt qilinadi: bcrypt_sha256$$2b$12$...
+type DjangoBcryptSHA256 struct {
+	Bcrypt
+}
+
+func (d *DjangoBcryptSHA256) Name() string {
+	return "bcrypt_sha256"
+}
+
+func (d *DjangoBcryptSHA256) Identify(encoded string) bool {
+	return strings.HasPrefix(encoded, "bcrypt_sha256$")
+}
+
+func (d *DjangoBcryptSHA256) Hash(password string) (string, error) {
+	hash, err := d.Bcrypt.Hash(sha256Hex(password))
+	if err != nil {
+		return "", err
+	}
+	return "bcrypt_sha256$" + hash, nil
+}
+
+func (d *DjangoBcryptSHA256) Verify(password string, encoded string) (bool, error) {
+	return d.Bcrypt.Verify(sha256Hex(password), strings.TrimPrefix(encoded, "bcrypt_sha256$"))
+}
+
+func (d *DjangoBcryptSHA256) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(strings.TrimPrefix(encoded, "bcrypt_sha256$")))
+	return err != nil || cost != d.Cost
+}
+
+func sha256Hex(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+// secureSalt Django salt alifbosidagi 22 belgili tasodifiy qator.
+func secureSalt() string {
+	return rand.Text()[:22]
+}
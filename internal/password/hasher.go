@@ -0,0 +1,250 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+type PasswordHasher interface {
+	Hash(string) (string, error)
+	Verify(string, string) (bool, error)
+	NeedsRehash(string) bool
+}
+
+// Algorithm bitta hash formati. Hash o'z algoritmi va parametrlarini saqlaydi (PHC yoki
+// bcrypt modular crypt formati), shuning uchun sozlama o'zgarsa eski hashlar ham tekshiriladi.
+type Algorithm interface {
+	PasswordHasher
+	Name() string
+	Identify(string) bool
+}
+
+// Hasher yangi parollarni asosiy algoritm bilan hashlaydi, qolgan algoritmlar faqat
+// mavjud hashlarni tekshirish va keyin qayta hashlash uchun.
+type Hasher struct {
+	preferred  Algorithm
+	algorithms []Algorithm
+}
+
+func NewHasher(preferred Algorithm, legacy ...Algorithm) *Hasher {
+	return &Hasher{
+		preferred:  preferred,
+		algorithms: append([]Algorithm{preferred}, legacy...),
+	}
+}
+
+func NewHasherFromConfig(cfg *config.Config) (*Hasher, error) {
+	argon := &Argon2id{
+		Memory:  uint32(cfg.Argon2Memory),
+		Time:    uint32(cfg.Argon2Time),
+		Threads: uint8(cfg.Argon2Threads),
+	}
+	bcryptHasher := &Bcrypt{Cost: int(cfg.BcryptCost)}
+	if bcryptHasher.Cost < bcrypt.MinCost || bcryptHasher.Cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("invalid bcrypt cost %d", bcryptHasher.Cost)
+	}
+	if argon.Memory == 0 || argon.Time == 0 || argon.Threads == 0 {
+		return nil, errors.New("invalid argon2id parameters")
+	}
+	// Django ilovasi bilan umumiy accounts_user jadvalidagi hashlar ham tekshiriladi. Django
+	// formatlari Django ning o'z parametrlarida, aks holda ikki tomon bir-birining hashini
+	// har kirishda qayta hashlaydi.
+	algorithms := []Algorithm{
+		argon,
+		bcryptHasher,
+		&DjangoArgon2{Argon2id: Argon2id{Memory: djangoArgon2Memory, Time: djangoArgon2Time, Threads: djangoArgon2Threads}},
+		&DjangoPbkdf2{Iterations: djangoPbkdf2Iterations},
+		&DjangoBcryptSHA256{Bcrypt: Bcrypt{Cost: djangoBcryptRounds}},
+	}
+	for i, algorithm := range algorithms {
+		if algorithm.Name() == cfg.PasswordHasher {
+			legacy := append(slices.Clone(algorithms[:i]), algorithms[i+1:]...)
+			return NewHasher(algorithm, legacy...), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown password hasher %q", cfg.PasswordHasher)
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+func (h *Hasher) Verify(password string, encoded string) (bool, error) {
+	algorithm := h.identify(encoded)
+	if algorithm == nil {
+		return false, ErrUnknownHash
+	}
+	return algorithm.Verify(password, encoded)
+}
+
+// NeedsRehash boshqa algoritm yoki asosiy algoritmning kuchsizroq parametrlari bilan
+// yaratilgan hash uchun true. Django formatidagi hash faqat Django formatiga almashtiriladi,
+// aks holda Django ilovasi foydalanuvchini endi tekshira olmaydi.
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	if !h.preferred.Identify(encoded) {
+		current := h.identify(encoded)
+		return current == nil || !isDjango(current) || isDjango(h.preferred)
+	}
+	return h.preferred.NeedsRehash(encoded)
+}
+
+func isDjango(algorithm Algorithm) bool {
+	switch algorithm.(type) {
+	case *DjangoArgon2, *DjangoPbkdf2, *DjangoBcryptSHA256:
+		return true
+	}
+	return false
+}
+
+func (h *Hasher) identify(encoded string) Algorithm {
+	for _, algorithm := range h.algorithms {
+		if algorithm.Identify(encoded) {
+			return algorithm
+		}
+	}
+	return nil
+}
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// Argon2id hash PHC formatida: $argon2id$v=19$m=65536,t=3,p=2$salt$hash. Memory KiB da.
+type Argon2id struct {
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+}
+
+type argon2Params struct {
+	variant string
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func (a *Argon2id) Name() string {
+	return "argon2id"
+}
+
+func (a *Argon2id) Identify(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return a.hash(password, salt), nil
+}
+
+func (a *Argon2id) hash(password string, salt []byte) string {
+	key := argon2.IDKey([]byte(password), salt, a.Time, a.Memory, a.Threads, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, a.Memory, a.Time, a.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func (a *Argon2id) Verify(password string, encoded string) (bool, error) {
+	params, err := parseArgon2(encoded)
+	if err != nil || params.variant != "argon2id" {
+		return false, ErrUnknownHash
+	}
+	return params.verify(password), nil
+}
+
+func (a *Argon2id) NeedsRehash(encoded string) bool {
+	params, err := parseArgon2(encoded)
+	if err != nil {
+		return true
+	}
+	return params.weakerThan(a)
+}
+
+func (p *argon2Params) verify(password string) bool {
+	var key []byte
+	if p.variant == "argon2i" {
+		key = argon2.Key([]byte(password), p.salt, p.time, p.memory, p.threads, uint32(len(p.key)))
+	} else {
+		key = argon2.IDKey([]byte(password), p.salt, p.time, p.memory, p.threads, uint32(len(p.key)))
+	}
+	return subtle.ConstantTimeCompare(key, p.key) == 1
+}
+
+func (p *argon2Params) weakerThan(a *Argon2id) bool {
+	return p.variant != "argon2id" || p.memory < a.Memory || p.time < a.Time || p.threads < a.Threads || len(p.key) < argon2KeyLength
+}
+
+// parseArgon2 PHC qatorini o'qiydi, argon2i faqat Django ning eski hashlari uchun.
+func parseArgon2(encoded string) (*argon2Params, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || (parts[1] != "argon2id" && parts[1] != "argon2i") {
+		return nil, ErrUnknownHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, ErrUnknownHash
+	}
+	params := &argon2Params{variant: parts[1]}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return nil, ErrUnknownHash
+	}
+	var err error
+	if params.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, ErrUnknownHash
+	}
+	if params.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(params.key) == 0 {
+		return nil, ErrUnknownHash
+	}
+	return params, nil
+}
+
+type Bcrypt struct {
+	Cost int
+}
+
+func (b *Bcrypt) Name() string {
+	return "bcrypt"
+}
+
+func (b *Bcrypt) Identify(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (b *Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.Cost)
+	return string(hash), err
+}
+
+func (b *Bcrypt) Verify(password string, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword), errors.Is(err, bcrypt.ErrPasswordTooLong):
+		return false, nil
+	}
+	return false, err
+}
+
+func (b *Bcrypt) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost < b.Cost
+}
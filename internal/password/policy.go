@@ -0,0 +1,162 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/JscorpTech/auth/internal/config"
+)
+
+// bcrypt 72 baytdan keyingi qismini e'tiborsiz qoldiradi
+const bcryptMaxBytes = 72
+
+// Siyosat qoidalari, PolicyError da va validatsiya xatolari kalitida ishlatiladi.
+const (
+	RuleMinLength    = "min_length"
+	RuleMaxLength    = "max_length"
+	RuleLowercase    = "lowercase"
+	RuleUppercase    = "uppercase"
+	RuleDigit        = "digit"
+	RuleSymbol       = "symbol"
+	RulePersonalInfo = "personal_info"
+	RuleBreached     = "breached"
+)
+
+var ErrPolicy = errors.New("password does not meet the policy")
+
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+// PolicyError buzilgan barcha qoidalarni qaytaradi, mijoz hammasini birdan ko'rsata oladi.
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	return ErrPolicy.Error()
+}
+
+func (e *PolicyError) Unwrap() error {
+	return ErrPolicy
+}
+
+// FieldErrors utils.FormatValidationErrors uchun, kalit "password.<qoida>" ko'rinishida.
+func (e *PolicyError) FieldErrors() map[string]string {
+	fields := make(map[string]string, len(e.Violations))
+	for _, violation := range e.Violations {
+		fields["password."+violation.Rule] = violation.Message
+	}
+	return fields
+}
+
+// Identity parolda bo'lmasligi kerak bo'lgan shaxsiy ma'lumotlar.
+type Identity struct {
+	Phone     string
+	FirstName string
+	LastName  string
+	Email     string
+}
+
+type Policy struct {
+	minLength int
+	maxBytes  int
+	classes   []string
+	breached  BreachChecker
+}
+
+func NewPolicy(cfg *config.Config) (*Policy, error) {
+	policy := &Policy{
+		minLength: int(cfg.PasswordMinLength),
+		maxBytes:  int(cfg.PasswordMaxLength),
+		classes:   cfg.PasswordClasses,
+	}
+	if cfg.PasswordHasher == "bcrypt" {
+		policy.maxBytes = min(policy.maxBytes, bcryptMaxBytes)
+	}
+	for _, class := range policy.classes {
+		if _, ok := classCheckers[class]; !ok {
+			return nil, fmt.Errorf("unknown password character class %q", class)
+		}
+	}
+	if cfg.PasswordBreachedPath != "" {
+		breached, err := NewBreachCorpus(cfg.PasswordBreachedPath)
+		if err != nil {
+			return nil, err
+		}
+		policy.breached = breached
+	}
+	return policy, nil
+}
+
+var classCheckers = map[string]struct {
+	check   func(rune) bool
+	message string
+}{
+	RuleLowercase: {unicode.IsLower, "password must contain a lowercase letter"},
+	RuleUppercase: {unicode.IsUpper, "password must contain an uppercase letter"},
+	RuleDigit:     {unicode.IsDigit, "password must contain a digit"},
+	RuleSymbol: {func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+	}, "password must contain a symbol"},
+}
+
+// Check parolni barcha qoidalar bo'yicha tekshiradi. Qoidalar buzilsa *PolicyError,
+// breached korpusni o'qishda muammo bo'lsa oddiy xato qaytadi.
+func (p *Policy) Check(password string, identity Identity) error {
+	var violations []Violation
+	if len([]rune(password)) < p.minLength {
+		violations = append(violations, Violation{RuleMinLength, fmt.Sprintf("password must be at least %d characters", p.minLength)})
+	}
+	if len(password) > p.maxBytes {
+		violations = append(violations, Violation{RuleMaxLength, fmt.Sprintf("password must be at most %d bytes", p.maxBytes)})
+	}
+	for _, class := range p.classes {
+		checker := classCheckers[class]
+		if !strings.ContainsFunc(password, checker.check) {
+			violations = append(violations, Violation{class, checker.message})
+		}
+	}
+	if containsPersonalInfo(password, identity) {
+		violations = append(violations, Violation{RulePersonalInfo, "password must not contain your phone number, name or email"})
+	}
+	if len(violations) == 0 && p.breached != nil {
+		breached, err := p.breached.Breached(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			violations = append(violations, Violation{RuleBreached, "password has appeared in a data breach"})
+		}
+	}
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// containsPersonalInfo telefonning oxirgi 7 raqami (mahalliy raqam) va 3 harfdan uzun
+// ism, familiya, email login qismi katta-kichik harfga qaramay tekshiriladi.
+func containsPersonalInfo(password string, identity Identity) bool {
+	lower := strings.ToLower(password)
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, identity.Phone)
+	if len(digits) >= 7 && strings.Contains(lower, digits[len(digits)-7:]) {
+		return true
+	}
+	local, _, _ := strings.Cut(identity.Email, "@")
+	for _, value := range []string{identity.FirstName, identity.LastName, local} {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if len([]rune(value)) >= 3 && strings.Contains(lower, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/nyaruka/phonenumbers"
+)
+
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+// Parser foydalanuvchi kiritgan raqamni E.164 ko'rinishiga ("+998901234567") keltiradi.
+// Bazada va limit kalitlarida raqam faqat shu ko'rinishda saqlanadi.
+type Parser interface {
+	Normalize(string) (string, error)
+}
+
+// NewParser PHONE_PARSER bo'yicha parser yaratadi.
+func NewParser(cfg *config.Config) (Parser, error) {
+	switch cfg.PhoneParser {
+	case "libphonenumber":
+		return NewLibphonenumber(cfg.PhoneDefaultRegion), nil
+	case "e164":
+		return NewE164(), nil
+	}
+	return nil, fmt.Errorf("unknown phone parser %q", cfg.PhoneParser)
+}
+
+// Libphonenumber mamlakat kodisiz raqamlarni ("90 123 45 67") region bo'yicha to'ldiradi
+// va raqam shu mamlakatning haqiqiy raqamlar oralig'ida ekanini tekshiradi.
+type Libphonenumber struct {
+	region string
+}
+
+func NewLibphonenumber(region string) *Libphonenumber {
+	return &Libphonenumber{region: region}
+}
+
+func (p *Libphonenumber) Normalize(raw string) (string, error) {
+	number, err := phonenumbers.Parse(raw, p.region)
+	if err != nil || !phonenumbers.IsValidNumber(number) {
+		return "", ErrInvalidPhone
+	}
+	return phonenumbers.Format(number, phonenumbers.E164), nil
+}
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// E164 faqat tayyor E.164 raqamlarni qabul qiladi, mijoz raqamni o'zi formatlaganda.
+type E164 struct{}
+
+func NewE164() *E164 {
+	return &E164{}
+}
+
+func (p *E164) Normalize(raw string) (string, error) {
+	if !e164Pattern.MatchString(raw) {
+		return "", ErrInvalidPhone
+	}
+	return raw, nil
+}
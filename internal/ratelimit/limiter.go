@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Limit Window davomida ruxsat etilgan so'rovlar soni.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter rad etilgan so'rov qancha vaqtdan keyin o'tishi mumkin
+	RetryAfter time.Duration
+	// Reset limit to'liq tiklanishigacha qolgan vaqt
+	Reset time.Duration
+}
+
+type Limiter interface {
+	Allow(context.Context, string, Limit) (Result, error)
+}
+
+// NewLimiter config dagi RATE_LIMIT_BACKEND bo'yicha limiter yaratadi. sql bir nechta
+// instansiya umumiy hisoblagichdan foydalanishi uchun.
+func NewLimiter(ctx context.Context, cfg *config.Config, db *gorm.DB, logger *zap.Logger) (Limiter, error) {
+	switch cfg.RateLimitBackend {
+	case "token_bucket":
+		return NewTokenBucket(ctx, logger), nil
+	case "sliding_window":
+		return NewSlidingWindow(ctx, logger), nil
+	case "sql":
+		return NewSQL(ctx, db, logger), nil
+	}
+	return nil, fmt.Errorf("unknown rate limit backend %q", cfg.RateLimitBackend)
+}
+
+// slidingWindow oldingi oyna hisobini qolgan ulushi bilan joriy oynaga qo'shib baholaydi,
+// shuning uchun oyna chegarasida fixed window dagi kabi ikki barobar so'rov o'tmaydi.
+// current joriy so'rovni o'z ichiga olmaydi.
+func slidingWindow(limit Limit, previous int, current int, elapsed time.Duration) Result {
+	weight := float64(limit.Window-elapsed) / float64(limit.Window)
+	estimate := float64(previous)*weight + float64(current)
+	result := Result{
+		Limit: limit.Requests,
+		Reset: limit.Window - elapsed,
+	}
+	if estimate+1 > float64(limit.Requests) {
+		result.RetryAfter = slidingRetryAfter(limit, previous, current, elapsed)
+		return result
+	}
+	result.Allowed = true
+	result.Remaining = max(limit.Requests-int(math.Ceil(estimate+1)), 0)
+	return result
+}
+
+// slidingRetryAfter oldingi oyna ulushi kamayib bitta so'rovga joy ochiladigan vaqt.
+func slidingRetryAfter(limit Limit, previous int, current int, elapsed time.Duration) time.Duration {
+	free := float64(limit.Requests - 1 - current)
+	if free < 0 || previous == 0 {
+		return limit.Window - elapsed
+	}
+	at := time.Duration(float64(limit.Window) * (1 - free/float64(previous)))
+	return max(at-elapsed, time.Second)
+}
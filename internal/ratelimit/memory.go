@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// bucket o'z oynasini saqlaydi: oxirgi so'rovdan keyin window o'tsa chelak to'lgan
+// bo'ladi va kalitni xotiradan o'chirish mumkin.
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+	window   time.Duration
+}
+
+// TokenBucket har bir kalit uchun Requests hajmli chelak, tokenlar Window davomida
+// bir tekis to'ladi. Faqat bitta instansiya uchun.
+type TokenBucket struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func NewTokenBucket(ctx context.Context, logger *zap.Logger) *TokenBucket {
+	t := &TokenBucket{buckets: make(map[string]*bucket)}
+	go cleanup(ctx, logger, func(now time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		for key, b := range t.buckets {
+			if now.Sub(b.lastSeen) > b.window {
+				delete(t.buckets, key)
+			}
+		}
+	})
+	return t
+}
+
+func (t *TokenBucket) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Window.Seconds()
+	b, ok := t.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, lastSeen: now}
+		t.buckets[key] = b
+	}
+	b.tokens = min(capacity, b.tokens+now.Sub(b.lastSeen).Seconds()*rate)
+	b.lastSeen = now
+	b.window = limit.Window
+	result := Result{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+	return result, nil
+}
+
+// window joriy oyna tugagandan keyin ham bitta oyna davomida hisobga olinadi
+// (previous), shuning uchun start dan 2*length o'tgach o'chiriladi.
+type window struct {
+	start    time.Time
+	length   time.Duration
+	previous int
+	current  int
+}
+
+// SlidingWindow sliding window counter, faqat bitta instansiya uchun.
+type SlidingWindow struct {
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+func NewSlidingWindow(ctx context.Context, logger *zap.Logger) *SlidingWindow {
+	s := &SlidingWindow{windows: make(map[string]*window)}
+	go cleanup(ctx, logger, func(now time.Time) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for key, w := range s.windows {
+			if now.Sub(w.start) > 2*w.length {
+				delete(s.windows, key)
+			}
+		}
+	})
+	return s
+}
+
+func (s *SlidingWindow) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	start := now.Truncate(limit.Window)
+	w, ok := s.windows[key]
+	if !ok {
+		w = &window{start: start, length: limit.Window}
+		s.windows[key] = w
+	}
+	if !w.start.Equal(start) {
+		if start.Sub(w.start) == limit.Window {
+			w.previous = w.current
+		} else {
+			w.previous = 0
+		}
+		w.current = 0
+		w.start = start
+	}
+	w.length = limit.Window
+	result := slidingWindow(limit, w.previous, w.current, now.Sub(start))
+	if result.Allowed {
+		w.current++
+	}
+	return result, nil
+}
+
+func cleanup(ctx context.Context, logger *zap.Logger, fn func(time.Time)) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Rate limiter cleanup goroutine stopped")
+			return
+		case now := <-ticker.C:
+			fn(now)
+		}
+	}
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
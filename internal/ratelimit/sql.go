@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Counter kalit bo'yicha bitta oynadagi so'rovlar soni.
+type Counter struct {
+	Key         string `gorm:"column:limit_key;primaryKey;size:191"`
+	WindowStart int64  `gorm:"column:window_start;primaryKey;autoIncrement:false"`
+	Count       int    `gorm:"column:count"`
+	ExpiresAt   int64  `gorm:"column:expires_at;index"`
+}
+
+func (*Counter) TableName() string {
+	return "rate_limit_counter"
+}
+
+// SQL sliding window counter bazada saqlanadi, shuning uchun barcha replikalar bitta
+// limitni bo'lishadi. Hisoblagich atomik upsert bilan oshiriladi va so'rov rad etilsa qaytariladi.
+type SQL struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewSQL(ctx context.Context, db *gorm.DB, logger *zap.Logger) *SQL {
+	s := &SQL{db: db, logger: logger}
+	go cleanup(ctx, logger, func(now time.Time) {
+		if err := db.WithContext(ctx).Where("expires_at < ?", now.Unix()).Delete(&Counter{}).Error; err != nil {
+			logger.Error("rate limit cleanup error", zap.Error(err))
+		}
+	})
+	return s
+}
+
+func (s *SQL) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := time.Now()
+	start := now.Truncate(limit.Window)
+	previousStart := start.Add(-limit.Window)
+	var result Result
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "limit_key"}, {Name: "window_start"}},
+			DoUpdates: clause.Assignments(map[string]any{"count": gorm.Expr("rate_limit_counter.count + 1")}),
+		}).Create(&Counter{
+			Key:         key,
+			WindowStart: start.UnixNano(),
+			Count:       1,
+			ExpiresAt:   start.Add(2 * limit.Window).Unix(),
+		}).Error
+		if err != nil {
+			return err
+		}
+		var counters []Counter
+		err = tx.Where("limit_key = ? and window_start in ?", key, []int64{previousStart.UnixNano(), start.UnixNano()}).Find(&counters).Error
+		if err != nil {
+			return err
+		}
+		var previous, current int
+		for _, counter := range counters {
+			if counter.WindowStart == start.UnixNano() {
+				current = counter.Count
+			} else {
+				previous = counter.Count
+			}
+		}
+		// current endi shu so'rovni ham o'z ichiga oladi
+		result = slidingWindow(limit, previous, current-1, now.Sub(start))
+		if result.Allowed {
+			return nil
+		}
+		return tx.Model(&Counter{}).
+			Where("limit_key = ? and window_start = ?", key, start.UnixNano()).
+			UpdateColumn("count", gorm.Expr("count - 1")).Error
+	})
+	return result, err
+}
@@ -0,0 +1,92 @@
+package social
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/JscorpTech/auth/internal/config"
+)
+
+// GithubProvider GitHub OIDC ID token bermaydi, shuning uchun OAuth access token bilan
+// API dan foydalanuvchi ma'lumotlari olinadi. Token aynan bizning OAuth ilovamizga
+// tegishli ekani ClientSecret bilan tekshiriladi, aks holda boshqa ilovaga berilgan
+// token bilan uning egasi nomidan kirish mumkin bo'lardi.
+type GithubProvider struct {
+	apiURL       string
+	clientID     string
+	clientSecret string
+}
+
+func NewGithub(cfg config.SocialProvider) *GithubProvider {
+	return &GithubProvider{
+		apiURL:       strings.TrimSuffix(cfg.Issuer, "/"),
+		clientID:     cfg.ClientID[0],
+		clientSecret: cfg.ClientSecret,
+	}
+}
+
+func (p *GithubProvider) Name() string {
+	return "github"
+}
+
+func (p *GithubProvider) Verify(ctx context.Context, token string) (*Identity, error) {
+	if err := p.checkApplication(ctx, token); err != nil {
+		return nil, ErrInvalidToken
+	}
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := p.get(ctx, token, "/user", &user); err != nil || user.ID == 0 {
+		return nil, ErrInvalidToken
+	}
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.get(ctx, token, "/user/emails", &emails); err != nil {
+		return nil, ErrInvalidToken
+	}
+	identity := &Identity{
+		Provider: p.Name(),
+		Subject:  strconv.FormatInt(user.ID, 10),
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = strings.ToLower(email.Email)
+			identity.EmailVerified = email.Verified
+		}
+	}
+	name := user.Name
+	if name == "" {
+		name = user.Login
+	}
+	identity.FirstName, identity.LastName, _ = strings.Cut(name, " ")
+	return identity, nil
+}
+
+func (p *GithubProvider) get(ctx context.Context, token string, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	return doJSON(req, out)
+}
+
+func (p *GithubProvider) checkApplication(ctx context.Context, token string) error {
+	body := strings.NewReader(fmt.Sprintf(`{"access_token":%q}`, token))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiURL+"/applications/"+p.clientID+"/token", body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.clientID, p.clientSecret)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	return doJSON(req, &struct{}{})
+}
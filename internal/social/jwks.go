@@ -0,0 +1,169 @@
+package social
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	jwksTTL          = time.Hour
+	jwksRefreshDelay = time.Minute
+)
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwks issuer kalitlarini keshlaydi. Noma'lum kid kelsa (kalit rotatsiyasi) kalitlar
+// qayta yuklanadi, lekin jwksRefreshDelay dan tez-tez emas.
+type jwks struct {
+	issuer  string
+	url     string
+	mu      sync.Mutex
+	keys    map[string]any
+	fetched time.Time
+}
+
+func newJWKS(issuer string, url string) *jwks {
+	return &jwks{issuer: issuer, url: url}
+}
+
+func (j *jwks) key(ctx context.Context, kid string) (any, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if key, ok := j.keys[kid]; ok && time.Since(j.fetched) < jwksTTL {
+		return key, nil
+	}
+	if time.Since(j.fetched) > jwksRefreshDelay {
+		if err := j.refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if key, ok := j.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+func (j *jwks) refresh(ctx context.Context) error {
+	if j.url == "" {
+		url, err := discoverJWKS(ctx, j.issuer)
+		if err != nil {
+			return err
+		}
+		j.url = url
+	}
+	var body struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, j.url, &body); err != nil {
+		return err
+	}
+	keys := make(map[string]any, len(body.Keys))
+	for _, jwk := range body.Keys {
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	j.keys = keys
+	j.fetched = time.Now()
+	return nil
+}
+
+func discoverJWKS(ctx context.Context, issuer string) (string, error) {
+	var body struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := getJSON(ctx, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &body); err != nil {
+		return "", err
+	}
+	if body.JWKSURI == "" {
+		return "", errors.New("jwks_uri not found in openid configuration")
+	}
+	return body.JWKSURI, nil
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+func getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	return doJSON(req, out)
+}
+
+func doJSON(req *http.Request, out any) error {
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: unexpected status %d", req.Method, req.URL, res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -0,0 +1,83 @@
+package social
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCProvider ID tokenni issuer JWKS kalitlari bilan tekshiradi. Google, Apple va
+// boshqa har qanday OpenID Connect provider shu orqali ishlaydi.
+type OIDCProvider struct {
+	name     string
+	issuers  []string
+	clientID []string
+	claims   ClaimMapping
+	jwks     *jwks
+}
+
+func NewOIDC(cfg config.SocialProvider) *OIDCProvider {
+	return &OIDCProvider{
+		name:     cfg.Name,
+		issuers:  []string{cfg.Issuer},
+		clientID: cfg.ClientID,
+		claims:   claimMapping(cfg.Claims),
+		jwks:     newJWKS(cfg.Issuer, cfg.JWKSURL),
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+func (p *OIDCProvider) Verify(ctx context.Context, token string) (*Identity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.jwks.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithAudience(p.clientID...),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	issuer, _ := claims.GetIssuer()
+	if !slices.Contains(p.issuers, issuer) {
+		return nil, ErrInvalidToken
+	}
+	identity := &Identity{
+		Provider:      p.name,
+		Subject:       stringClaim(claims, p.claims.Subject),
+		Email:         strings.ToLower(stringClaim(claims, p.claims.Email)),
+		EmailVerified: boolClaim(claims, p.claims.EmailVerified),
+		FirstName:     stringClaim(claims, p.claims.FirstName),
+		LastName:      stringClaim(claims, p.claims.LastName),
+	}
+	if identity.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	return identity, nil
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// boolClaim Apple email_verified ni "true" satri ko'rinishida beradi.
+func boolClaim(claims jwt.MapClaims, name string) bool {
+	switch value := claims[name].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,100 @@
+package social
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JscorpTech/auth/internal/config"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	ErrInvalidToken    = errors.New("invalid identity token")
+)
+
+// Identity provider tasdiqlagan foydalanuvchi ma'lumotlari.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+}
+
+type IdentityProvider interface {
+	Name() string
+	Verify(context.Context, string) (*Identity, error)
+}
+
+// ClaimMapping ID token claimlarini Identity maydonlariga bog'laydi.
+type ClaimMapping struct {
+	Subject       string
+	Email         string
+	EmailVerified string
+	FirstName     string
+	LastName      string
+}
+
+func DefaultClaimMapping() ClaimMapping {
+	return ClaimMapping{
+		Subject:       "sub",
+		Email:         "email",
+		EmailVerified: "email_verified",
+		FirstName:     "given_name",
+		LastName:      "family_name",
+	}
+}
+
+func claimMapping(overrides map[string]string) ClaimMapping {
+	mapping := DefaultClaimMapping()
+	for field, claim := range overrides {
+		switch field {
+		case "subject":
+			mapping.Subject = claim
+		case "email":
+			mapping.Email = claim
+		case "email_verified":
+			mapping.EmailVerified = claim
+		case "first_name":
+			mapping.FirstName = claim
+		case "last_name":
+			mapping.LastName = claim
+		}
+	}
+	return mapping
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// NewProviders sozlangan har bir provider uchun IdentityProvider yaratadi. Client ID
+// (GitHub uchun client secret ham) berilmagan providerlar o'tkazib yuboriladi.
+func NewProviders(cfg *config.Config, logger *zap.Logger) map[string]IdentityProvider {
+	providers := make(map[string]IdentityProvider)
+	for name, providerCfg := range cfg.SocialProviders {
+		if len(providerCfg.ClientID) == 0 {
+			logger.Info("social provider client id is not set, skipped", zap.String("provider", name))
+			continue
+		}
+		switch name {
+		case "github":
+			if providerCfg.ClientSecret == "" {
+				logger.Error("github provider requires client secret, skipped")
+				continue
+			}
+			providers[name] = NewGithub(providerCfg)
+		case "google":
+			provider := NewOIDC(providerCfg)
+			// Google ba'zi tokenlarda iss ni sxemasiz beradi.
+			provider.issuers = append(provider.issuers, strings.TrimPrefix(providerCfg.Issuer, "https://"))
+			providers[name] = provider
+		default:
+			providers[name] = NewOIDC(providerCfg)
+		}
+	}
+	return providers
+}
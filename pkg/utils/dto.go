@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"errors"
+	"maps"
 	"reflect"
 	"strings"
 
@@ -15,9 +17,20 @@ func getJSONFieldName(structField reflect.StructField) string {
 	return strings.Split(tag, ",")[0]
 }
 
+// fieldErrors validator dan tashqaridagi tekshiruvlar (masalan parol siyosati) xatolari
+// shu interfeys orqali javobga qo'shiladi.
+type fieldErrors interface {
+	FieldErrors() map[string]string
+}
+
 func FormatValidationErrors(err error, obj interface{}) map[string]string {
 	errorsMap := make(map[string]string)
 
+	var custom fieldErrors
+	if errors.As(err, &custom) {
+		maps.Copy(errorsMap, custom.FieldErrors())
+	}
+
 	if validationErrors, ok := err.(validator.ValidationErrors); ok {
 		val := reflect.TypeOf(obj).Elem()
 		for _, fieldErr := range validationErrors {
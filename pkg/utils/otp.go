@@ -1,6 +1,8 @@
 package utils
 
 import (
+	crand "crypto/rand"
+	"math/big"
 	"math/rand"
 )
 
@@ -16,3 +18,17 @@ func RandomString(length int, chars string) string {
 func RandomOtp(length int) string {
 	return RandomString(length, "1234567890")
 }
+
+// SecureRandomString maxfiy qiymatlar (recovery kodlar, link tokenlar) uchun crypto/rand ishlatadi.
+func SecureRandomString(length int, chars string) (string, error) {
+	result := make([]byte, length)
+	max := big.NewInt(int64(len(chars)))
+	for i := range length {
+		n, err := crand.Int(crand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		result[i] = chars[n.Int64()]
+	}
+	return string(result), nil
+}
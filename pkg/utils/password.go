@@ -1,14 +1,12 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
-
-func HashPassword(password string) (string, error) {
-	passwordByte, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(passwordByte), err
-}
-
-func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashToken yuqori entropiyali tasodifiy tokenlar uchun. Parollar password.Hasher orqali hashlanadi.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
-
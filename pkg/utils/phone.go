@@ -0,0 +1,22 @@
+package utils
+
+import "strings"
+
+// PhoneDigits raqamdan +, bo'sh joy va boshqa belgilarni olib tashlaydi ("+998 90..." -> "99890...").
+func PhoneDigits(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+}
+
+// PhonePrefix raqamning birinchi length ta raqami, masalan 99890 (mamlakat va operator kodi).
+func PhonePrefix(phone string, length int) string {
+	digits := PhoneDigits(phone)
+	if len(digits) > length {
+		return digits[:length]
+	}
+	return digits
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+// VerifyTelegramLogin Telegram Login Widget ma'lumotlarini tekshiradi.
+// data_check_string hash dan boshqa barcha maydonlardan alifbo tartibida "key=value"
+// ko'rinishida "\n" bilan yig'iladi, kalit esa bot tokenning SHA-256 hashi.
+// https://core.telegram.org/widgets/login#checking-authorization
+func VerifyTelegramLogin(data map[string]string, hash string, botToken string) bool {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, key+"="+data[key])
+	}
+	secret := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	expected, err := hex.DecodeString(hash)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(mac.Sum(nil), expected)
+}